@@ -52,6 +52,7 @@ import (
 	jprom "github.com/uber/jaeger-lib/metrics/prometheus"
 	"go.uber.org/atomic"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
+	yaml "gopkg.in/yaml.v2"
 	klog "k8s.io/klog"
 	klogv2 "k8s.io/klog/v2"
 
@@ -70,6 +71,8 @@ import (
 	"github.com/adolli/prometheus/storage"
 	"github.com/adolli/prometheus/storage/remote"
 	"github.com/adolli/prometheus/tsdb"
+	"github.com/adolli/prometheus/tsdb/agent"
+	"github.com/adolli/prometheus/tsdb/wal"
 	"github.com/adolli/prometheus/util/strutil"
 	"github.com/adolli/prometheus/web"
 )
@@ -115,11 +118,16 @@ type flagConfig struct {
 	queryMaxSamples     int
 	RemoteFlushDeadline model.Duration
 
+	secondaryErrorPolicy string
+
 	featureList []string
 	// These options are extracted from featureList
 	// for ease of use.
 	enablePromQLAtModifier     bool
 	enablePromQLNegativeOffset bool
+	enableDynamicConfig        bool
+	enableAgentMode            bool
+	enableExpandEnvVars        bool
 
 	prometheusURL   string
 	corsRegexString string
@@ -145,6 +153,15 @@ func (c *flagConfig) setFeatureListOptions(logger log.Logger) error {
 			case "remote-write-receiver":
 				c.web.RemoteWriteReceiver = true
 				level.Info(logger).Log("msg", "Experimental remote-write-receiver enabled")
+			case "dynamic-config":
+				c.enableDynamicConfig = true
+				level.Info(logger).Log("msg", "Experimental dynamic configuration API enabled")
+			case "agent":
+				c.enableAgentMode = true
+				level.Info(logger).Log("msg", "Experimental agent mode enabled, local storage keeps the WAL only")
+			case "expand-env-vars":
+				c.enableExpandEnvVars = true
+				level.Info(logger).Log("msg", "Experimental expansion of ${ENV_VAR} references in the configuration file enabled")
 			case "exemplar-storage":
 				c.tsdb.MaxExemplars = maxExemplars
 				level.Info(logger).Log("msg", "Experimental in-memory exemplar storage enabled")
@@ -262,9 +279,21 @@ func main() {
 	a.Flag("storage.tsdb.wal-compression", "Compress the tsdb WAL.").
 		Default("true").BoolVar(&cfg.tsdb.WALCompression)
 
+	a.Flag("storage.tsdb.wal-sync-policy", "When to fsync the tsdb WAL beyond segment rotation. One of: rotation, always, interval, batch-size.").
+		Hidden().Default(string(wal.SyncRotation)).EnumVar(&cfg.tsdb.WALSyncMode, string(wal.SyncRotation), string(wal.SyncAlways), string(wal.SyncInterval), string(wal.SyncBatchSize))
+
+	a.Flag("storage.tsdb.wal-sync-interval", "Interval between WAL fsyncs when --storage.tsdb.wal-sync-policy=interval.").
+		Hidden().Default("1s").PlaceHolder("<duration>").SetValue(&cfg.tsdb.WALSyncInterval)
+
+	a.Flag("storage.tsdb.wal-sync-batch-size", "Number of WAL append batches between fsyncs when --storage.tsdb.wal-sync-policy=batch-size.").
+		Hidden().Default("64").IntVar(&cfg.tsdb.WALSyncBatchSize)
+
 	a.Flag("storage.remote.flush-deadline", "How long to wait flushing sample on shutdown or config reload.").
 		Default("1m").PlaceHolder("<duration>").SetValue(&cfg.RemoteFlushDeadline)
 
+	a.Flag("storage.fanout.secondary-error-policy", "How to handle errors from secondary (remote) storage appenders. One of: fail-commit, log-and-continue, circuit-break.").
+		Hidden().Default(string(storage.FailCommit)).EnumVar(&cfg.secondaryErrorPolicy, string(storage.FailCommit), string(storage.LogAndContinue), string(storage.CircuitBreak))
+
 	a.Flag("storage.remote.read-sample-limit", "Maximum overall number of samples to return via the remote read interface, in a single query. 0 means no limit. This limit is ignored for streamed response types.").
 		Default("5e7").IntVar(&cfg.web.RemoteReadSampleLimit)
 
@@ -307,7 +336,7 @@ func main() {
 	a.Flag("query.max-samples", "Maximum number of samples a single query can load into memory. Note that queries will fail if they try to load more samples than this into memory, so this also limits the number of samples a query can return.").
 		Default("50000000").IntVar(&cfg.queryMaxSamples)
 
-	a.Flag("enable-feature", "Comma separated feature names to enable. Valid options: 'promql-at-modifier' to enable the @ modifier, 'remote-write-receiver' to enable remote write receiver, 'exemplar-storage' to enable the in-memory exemplar storage. See https://prometheus.io/docs/prometheus/latest/disabled_features/ for more details.").
+	a.Flag("enable-feature", "Comma separated feature names to enable. Valid options: 'promql-at-modifier' to enable the @ modifier, 'remote-write-receiver' to enable remote write receiver, 'exemplar-storage' to enable the in-memory exemplar storage, 'agent' to run a WAL-only storage for remote_write-only deployments, 'expand-env-vars' to expand ${ENV_VAR} references in the configuration file. See https://prometheus.io/docs/prometheus/latest/disabled_features/ for more details.").
 		Default("").StringsVar(&cfg.featureList)
 
 	promlogflag.AddFlags(a, &cfg.promlogConfig)
@@ -343,7 +372,7 @@ func main() {
 	}
 
 	// Throw error for invalid config before starting other components.
-	if _, err := config.LoadFile(cfg.configFile); err != nil {
+	if _, err := config.LoadFileExpanded(cfg.configFile, cfg.enableExpandEnvVars); err != nil {
 		level.Error(logger).Log("msg", fmt.Sprintf("Error loading config (--config.file=%s)", cfg.configFile), "err", err)
 		os.Exit(2)
 	}
@@ -429,7 +458,7 @@ func main() {
 		localStorage  = &readyStorage{}
 		scraper       = &readyScrapeManager{}
 		remoteStorage = remote.NewStorage(log.With(logger, "component", "remote"), prometheus.DefaultRegisterer, localStorage.StartTime, cfg.localStoragePath, time.Duration(cfg.RemoteFlushDeadline), scraper)
-		fanoutStorage = storage.NewFanout(logger, localStorage, remoteStorage)
+		fanoutStorage = storage.NewFanoutWithOptions(logger, prometheus.DefaultRegisterer, storage.SecondaryErrorPolicy(cfg.secondaryErrorPolicy), localStorage, remoteStorage)
 	)
 
 	var (
@@ -481,14 +510,31 @@ func main() {
 	cfg.web.TSDBRetentionDuration = cfg.tsdb.RetentionDuration
 	cfg.web.TSDBMaxBytes = cfg.tsdb.MaxBytes
 	cfg.web.TSDBDir = cfg.localStoragePath
+	if cfg.enableDynamicConfig {
+		cfg.web.DynamicConfigDir = filepath.Join(cfg.localStoragePath, "dynamic-config")
+	}
 	cfg.web.LocalStorage = localStorage
 	cfg.web.Storage = fanoutStorage
-	cfg.web.ExemplarStorage = localStorage
+	// Serve exemplars from the local storage merged with any exemplars the
+	// remote read endpoints return, so a global view Prometheus can federate
+	// exemplars stored in leaf servers.
+	cfg.web.ExemplarStorage = storage.NewMergeExemplarQueryable(localStorage, remoteStorage)
 	cfg.web.QueryEngine = queryEngine
 	cfg.web.ScrapeManager = scrapeManager
 	cfg.web.RuleManager = ruleManager
 	cfg.web.Notifier = notifierManager
 	cfg.web.LookbackDelta = time.Duration(cfg.lookbackDelta)
+	cfg.web.DrainRemoteWrite = remoteStorage.Drain
+	cfg.web.ConfigLoader = func() (*config.Config, error) {
+		conf, err := config.LoadFileExpanded(cfg.configFile, cfg.enableExpandEnvVars)
+		if err != nil {
+			return nil, err
+		}
+		if err := config.LoadDynamic(conf, cfg.web.DynamicConfigDir); err != nil {
+			return nil, err
+		}
+		return conf, nil
+	}
 
 	cfg.web.Version = &web.PrometheusVersion{
 		Version:   version.Version,
@@ -523,11 +569,23 @@ func main() {
 		{
 			name:     "remote_storage",
 			reloader: remoteStorage.ApplyConfig,
+			relevant: func(cfg *config.Config) interface{} {
+				return struct {
+					ExternalLabels labels.Labels
+					RemoteWrite    []*config.RemoteWriteConfig
+					RemoteRead     []*config.RemoteReadConfig
+				}{cfg.GlobalConfig.ExternalLabels, cfg.RemoteWriteConfigs, cfg.RemoteReadConfigs}
+			},
 		}, {
+			// The web handler serves the full configuration, so it has to
+			// see every change.
 			name:     "web_handler",
 			reloader: webHandler.ApplyConfig,
 		}, {
 			name: "query_engine",
+			relevant: func(cfg *config.Config) interface{} {
+				return cfg.GlobalConfig.QueryLogFile
+			},
 			reloader: func(cfg *config.Config) error {
 				if cfg.GlobalConfig.QueryLogFile == "" {
 					queryEngine.SetQueryLogger(nil)
@@ -546,8 +604,17 @@ func main() {
 			// they need to read the most updated config when receiving the new targets list.
 			name:     "scrape",
 			reloader: scrapeManager.ApplyConfig,
+			relevant: func(cfg *config.Config) interface{} {
+				return struct {
+					Global  config.GlobalConfig
+					Scrapes []*config.ScrapeConfig
+				}{cfg.GlobalConfig, cfg.ScrapeConfigs}
+			},
 		}, {
 			name: "scrape_sd",
+			relevant: func(cfg *config.Config) interface{} {
+				return cfg.ScrapeConfigs
+			},
 			reloader: func(cfg *config.Config) error {
 				c := make(map[string]discovery.Configs)
 				for _, v := range cfg.ScrapeConfigs {
@@ -558,8 +625,17 @@ func main() {
 		}, {
 			name:     "notify",
 			reloader: notifierManager.ApplyConfig,
+			relevant: func(cfg *config.Config) interface{} {
+				return struct {
+					ExternalLabels labels.Labels
+					Alerting       config.AlertingConfig
+				}{cfg.GlobalConfig.ExternalLabels, cfg.AlertingConfig}
+			},
 		}, {
 			name: "notify_sd",
+			relevant: func(cfg *config.Config) interface{} {
+				return cfg.AlertingConfig
+			},
 			reloader: func(cfg *config.Config) error {
 				c := make(map[string]discovery.Configs)
 				for k, v := range cfg.AlertingConfig.AlertmanagerConfigs.ToMap() {
@@ -568,7 +644,8 @@ func main() {
 				return discoveryManagerNotify.ApplyConfig(c)
 			},
 		}, {
-			name: "rules",
+			name:     "rules",
+			relevant: ruleFilesSnapshot,
 			reloader: func(cfg *config.Config) error {
 				// Get all rule files matching the configuration paths.
 				var files []string
@@ -721,11 +798,11 @@ func main() {
 				for {
 					select {
 					case <-hup:
-						if err := reloadConfig(cfg.configFile, logger, noStepSubqueryInterval, reloaders...); err != nil {
+						if err := reloadConfig(cfg.configFile, cfg.web.DynamicConfigDir, cfg.enableExpandEnvVars, logger, noStepSubqueryInterval, reloaders...); err != nil {
 							level.Error(logger).Log("msg", "Error reloading config", "err", err)
 						}
 					case rc := <-webHandler.Reload():
-						if err := reloadConfig(cfg.configFile, logger, noStepSubqueryInterval, reloaders...); err != nil {
+						if err := reloadConfig(cfg.configFile, cfg.web.DynamicConfigDir, cfg.enableExpandEnvVars, logger, noStepSubqueryInterval, reloaders...); err != nil {
 							level.Error(logger).Log("msg", "Error reloading config", "err", err)
 							rc <- err
 						} else {
@@ -757,7 +834,7 @@ func main() {
 					return nil
 				}
 
-				if err := reloadConfig(cfg.configFile, logger, noStepSubqueryInterval, reloaders...); err != nil {
+				if err := reloadConfig(cfg.configFile, cfg.web.DynamicConfigDir, cfg.enableExpandEnvVars, logger, noStepSubqueryInterval, reloaders...); err != nil {
 					return errors.Wrapf(err, "error loading config from %q", cfg.configFile)
 				}
 
@@ -786,7 +863,54 @@ func main() {
 			},
 		)
 	}
-	{
+	if cfg.enableAgentMode {
+		// WAL storage.
+		opts := agent.Options{
+			WALSegmentSize: int(cfg.tsdb.WALSegmentSize),
+			WALCompression: cfg.tsdb.WALCompression,
+		}
+		cancel := make(chan struct{})
+		g.Add(
+			func() error {
+				level.Info(logger).Log("msg", "Starting WAL storage ...")
+				if cfg.tsdb.WALSegmentSize != 0 {
+					if cfg.tsdb.WALSegmentSize < 10*1024*1024 || cfg.tsdb.WALSegmentSize > 256*1024*1024 {
+						return errors.New("flag 'storage.tsdb.wal-segment-size' must be set between 10MB and 256MB")
+					}
+				}
+				db, err := agent.Open(
+					log.With(logger, "component", "wal storage"),
+					prometheus.DefaultRegisterer,
+					remoteStorage,
+					cfg.localStoragePath,
+					&opts,
+				)
+				if err != nil {
+					return errors.Wrapf(err, "opening storage failed")
+				}
+
+				switch fsType := prom_runtime.Statfs(cfg.localStoragePath); fsType {
+				case "NFS_SUPER_MAGIC":
+					level.Warn(logger).Log("fs_type", fsType, "msg", "This filesystem is not supported and may lead to data corruption and data loss. Please carefully read https://prometheus.io/docs/prometheus/latest/storage/ to learn more about supported filesystems.")
+				default:
+					level.Info(logger).Log("fs_type", fsType)
+				}
+
+				level.Info(logger).Log("msg", "WAL storage started")
+
+				localStorage.SetAgent(db)
+				close(dbOpen)
+				<-cancel
+				return nil
+			},
+			func(err error) {
+				if err := fanoutStorage.Close(); err != nil {
+					level.Error(logger).Log("msg", "Error stopping storage", "err", err)
+				}
+				close(cancel)
+			},
+		)
+	} else {
 		// TSDB.
 		opts := cfg.tsdb.ToTSDBOptions()
 		cancel := make(chan struct{})
@@ -936,9 +1060,64 @@ func (i *safePromQLNoStepSubqueryInterval) Get(int64) int64 {
 type reloader struct {
 	name     string
 	reloader func(*config.Config) error
+	// relevant extracts the part of the configuration this reloader depends
+	// on. If set, the reloader is skipped when that part is unchanged since
+	// the previous successful reload. If nil, the reloader always runs.
+	relevant func(*config.Config) interface{}
+}
+
+// lastReloadFingerprints holds, per reloader, the YAML rendering of the
+// relevant config section at the previous successful reload. Reloads are
+// serialized by the caller.
+var lastReloadFingerprints = map[string]string{}
+
+// sectionFingerprint renders the part of the configuration a reloader depends
+// on for change detection. An empty string means the fingerprint could not be
+// computed and the reloader must run.
+func sectionFingerprint(relevant func(*config.Config) interface{}, cfg *config.Config) string {
+	b, err := yaml.Marshal(relevant(cfg))
+	if err != nil {
+		return ""
+	}
+	return string(b)
 }
 
-func reloadConfig(filename string, logger log.Logger, noStepSuqueryInterval *safePromQLNoStepSubqueryInterval, rls ...reloader) (err error) {
+// ruleFilesSnapshot resolves the rule file globs and captures the size and
+// modification time of every matched file, so that edited rule files trigger
+// a rules reload even when the configuration file itself is unchanged.
+func ruleFilesSnapshot(cfg *config.Config) interface{} {
+	type fileInfo struct {
+		Name    string
+		Size    int64
+		ModTime time.Time
+	}
+	snap := struct {
+		EvaluationInterval model.Duration
+		ExternalLabels     labels.Labels
+		Files              []fileInfo
+	}{
+		EvaluationInterval: cfg.GlobalConfig.EvaluationInterval,
+		ExternalLabels:     cfg.GlobalConfig.ExternalLabels,
+	}
+	for _, pat := range cfg.RuleFiles {
+		fs, err := filepath.Glob(pat)
+		if err != nil {
+			snap.Files = append(snap.Files, fileInfo{Name: pat})
+			continue
+		}
+		for _, f := range fs {
+			fi := fileInfo{Name: f}
+			if st, err := os.Stat(f); err == nil {
+				fi.Size = st.Size()
+				fi.ModTime = st.ModTime()
+			}
+			snap.Files = append(snap.Files, fi)
+		}
+	}
+	return snap
+}
+
+func reloadConfig(filename, dynamicDir string, expandEnvVars bool, logger log.Logger, noStepSuqueryInterval *safePromQLNoStepSubqueryInterval, rls ...reloader) (err error) {
 	start := time.Now()
 	timings := []interface{}{}
 	level.Info(logger).Log("msg", "Loading configuration file", "filename", filename)
@@ -952,13 +1131,28 @@ func reloadConfig(filename string, logger log.Logger, noStepSuqueryInterval *saf
 		}
 	}()
 
-	conf, err := config.LoadFile(filename)
+	conf, err := config.LoadFileExpanded(filename, expandEnvVars)
 	if err != nil {
 		return errors.Wrapf(err, "couldn't load configuration (--config.file=%q)", filename)
 	}
+	if err := config.LoadDynamic(conf, dynamicDir); err != nil {
+		return errors.Wrapf(err, "couldn't load dynamic configuration (%q)", dynamicDir)
+	}
+	if err := conf.ResolveSecrets(context.Background()); err != nil {
+		return errors.Wrap(err, "couldn't resolve secret references in configuration")
+	}
 
 	failed := false
+	fingerprints := map[string]string{}
 	for _, rl := range rls {
+		if rl.relevant != nil {
+			fp := sectionFingerprint(rl.relevant, conf)
+			fingerprints[rl.name] = fp
+			if fp != "" && fp == lastReloadFingerprints[rl.name] {
+				level.Debug(logger).Log("msg", "Skipping reload, relevant configuration unchanged", "name", rl.name)
+				continue
+			}
+		}
 		rstart := time.Now()
 		if err := rl.reloader(conf); err != nil {
 			level.Error(logger).Log("msg", "Failed to apply configuration", "err", err)
@@ -967,8 +1161,11 @@ func reloadConfig(filename string, logger log.Logger, noStepSuqueryInterval *saf
 		timings = append(timings, rl.name, time.Since(rstart))
 	}
 	if failed {
+		// Retry every reloader on the next attempt.
+		lastReloadFingerprints = map[string]string{}
 		return errors.Errorf("one or more errors occurred while applying the new configuration (--config.file=%q)", filename)
 	}
+	lastReloadFingerprints = fingerprints
 
 	noStepSuqueryInterval.Set(conf.GlobalConfig.EvaluationInterval)
 	l := []interface{}{"msg", "Completed loading of configuration file", "filename", filename, "totalDuration", time.Since(start)}
@@ -1058,6 +1255,7 @@ func sendAlerts(s sender, externalURL string) rules.NotifyFunc {
 type readyStorage struct {
 	mtx             sync.RWMutex
 	db              *tsdb.DB
+	agent           *agent.DB
 	startTimeMargin int64
 }
 
@@ -1070,6 +1268,14 @@ func (s *readyStorage) Set(db *tsdb.DB, startTimeMargin int64) {
 	s.startTimeMargin = startTimeMargin
 }
 
+// SetAgent sets the WAL-only storage used in agent mode.
+func (s *readyStorage) SetAgent(db *agent.DB) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.agent = db
+}
+
 // get is internal, you should use readyStorage as the front implementation layer.
 func (s *readyStorage) get() *tsdb.DB {
 	s.mtx.RLock()
@@ -1078,6 +1284,13 @@ func (s *readyStorage) get() *tsdb.DB {
 	return x
 }
 
+func (s *readyStorage) getAgent() *agent.DB {
+	s.mtx.RLock()
+	x := s.agent
+	s.mtx.RUnlock()
+	return x
+}
+
 // StartTime implements the Storage interface.
 func (s *readyStorage) StartTime() (int64, error) {
 	if x := s.get(); x != nil {
@@ -1091,6 +1304,9 @@ func (s *readyStorage) StartTime() (int64, error) {
 		// Add a safety margin as it may take a few minutes for everything to spin up.
 		return startTime + s.startTimeMargin, nil
 	}
+	if x := s.getAgent(); x != nil {
+		return x.StartTime()
+	}
 
 	return math.MaxInt64, tsdb.ErrNotReady
 }
@@ -1100,6 +1316,9 @@ func (s *readyStorage) Querier(ctx context.Context, mint, maxt int64) (storage.Q
 	if x := s.get(); x != nil {
 		return x.Querier(ctx, mint, maxt)
 	}
+	if x := s.getAgent(); x != nil {
+		return x.Querier(ctx, mint, maxt)
+	}
 	return nil, tsdb.ErrNotReady
 }
 
@@ -1108,6 +1327,9 @@ func (s *readyStorage) ChunkQuerier(ctx context.Context, mint, maxt int64) (stor
 	if x := s.get(); x != nil {
 		return x.ChunkQuerier(ctx, mint, maxt)
 	}
+	if x := s.getAgent(); x != nil {
+		return x.ChunkQuerier(ctx, mint, maxt)
+	}
 	return nil, tsdb.ErrNotReady
 }
 
@@ -1115,6 +1337,9 @@ func (s *readyStorage) ExemplarQuerier(ctx context.Context) (storage.ExemplarQue
 	if x := s.get(); x != nil {
 		return x.ExemplarQuerier(ctx)
 	}
+	if x := s.getAgent(); x != nil {
+		return x.ExemplarQuerier(ctx)
+	}
 	return nil, tsdb.ErrNotReady
 }
 
@@ -1123,6 +1348,9 @@ func (s *readyStorage) Appender(ctx context.Context) storage.Appender {
 	if x := s.get(); x != nil {
 		return x.Appender(ctx)
 	}
+	if x := s.getAgent(); x != nil {
+		return x.Appender(ctx)
+	}
 	return notReadyAppender{}
 }
 
@@ -1145,6 +1373,9 @@ func (s *readyStorage) Close() error {
 	if x := s.get(); x != nil {
 		return x.Close()
 	}
+	if x := s.getAgent(); x != nil {
+		return x.Close()
+	}
 	return nil
 }
 
@@ -1153,6 +1384,9 @@ func (s *readyStorage) CleanTombstones() error {
 	if x := s.get(); x != nil {
 		return x.CleanTombstones()
 	}
+	if s.getAgent() != nil {
+		return agent.ErrUnsupported
+	}
 	return tsdb.ErrNotReady
 }
 
@@ -1161,6 +1395,42 @@ func (s *readyStorage) Delete(mint, maxt int64, ms ...*labels.Matcher) error {
 	if x := s.get(); x != nil {
 		return x.Delete(mint, maxt, ms...)
 	}
+	if s.getAgent() != nil {
+		return agent.ErrUnsupported
+	}
+	return tsdb.ErrNotReady
+}
+
+// DeleteBlock implements the api_v1.TSDBAdminStats interface.
+func (s *readyStorage) DeleteBlock(id string) error {
+	if x := s.get(); x != nil {
+		return x.DeleteBlock(id)
+	}
+	if s.getAgent() != nil {
+		return agent.ErrUnsupported
+	}
+	return tsdb.ErrNotReady
+}
+
+// PinBlock implements the api_v1.TSDBAdminStats interface.
+func (s *readyStorage) PinBlock(id string) error {
+	if x := s.get(); x != nil {
+		return x.PinBlock(id)
+	}
+	if s.getAgent() != nil {
+		return agent.ErrUnsupported
+	}
+	return tsdb.ErrNotReady
+}
+
+// UnpinBlock implements the api_v1.TSDBAdminStats interface.
+func (s *readyStorage) UnpinBlock(id string) error {
+	if x := s.get(); x != nil {
+		return x.UnpinBlock(id)
+	}
+	if s.getAgent() != nil {
+		return agent.ErrUnsupported
+	}
 	return tsdb.ErrNotReady
 }
 
@@ -1169,13 +1439,19 @@ func (s *readyStorage) Snapshot(dir string, withHead bool) error {
 	if x := s.get(); x != nil {
 		return x.Snapshot(dir, withHead)
 	}
+	if s.getAgent() != nil {
+		return agent.ErrUnsupported
+	}
 	return tsdb.ErrNotReady
 }
 
 // Stats implements the api_v1.TSDBAdminStats interface.
-func (s *readyStorage) Stats(statsByLabelName string) (*tsdb.Stats, error) {
+func (s *readyStorage) Stats(statsByLabelName string, limit int) (*tsdb.Stats, error) {
 	if x := s.get(); x != nil {
-		return x.Head().Stats(statsByLabelName), nil
+		return x.Head().Stats(statsByLabelName, limit), nil
+	}
+	if s.getAgent() != nil {
+		return nil, agent.ErrUnsupported
 	}
 	return nil, tsdb.ErrNotReady
 }
@@ -1218,6 +1494,9 @@ type tsdbOptions struct {
 	NoLockfile             bool
 	AllowOverlappingBlocks bool
 	WALCompression         bool
+	WALSyncMode            string
+	WALSyncInterval        model.Duration
+	WALSyncBatchSize       int
 	StripeSize             int
 	MinBlockDuration       model.Duration
 	MaxBlockDuration       model.Duration
@@ -1232,10 +1511,15 @@ func (opts tsdbOptions) ToTSDBOptions() tsdb.Options {
 		NoLockfile:             opts.NoLockfile,
 		AllowOverlappingBlocks: opts.AllowOverlappingBlocks,
 		WALCompression:         opts.WALCompression,
-		StripeSize:             opts.StripeSize,
-		MinBlockDuration:       int64(time.Duration(opts.MinBlockDuration) / time.Millisecond),
-		MaxBlockDuration:       int64(time.Duration(opts.MaxBlockDuration) / time.Millisecond),
-		MaxExemplars:           opts.MaxExemplars,
+		WALSyncPolicy: wal.SyncPolicy{
+			Mode:      wal.SyncMode(opts.WALSyncMode),
+			Interval:  time.Duration(opts.WALSyncInterval),
+			BatchSize: opts.WALSyncBatchSize,
+		},
+		StripeSize:       opts.StripeSize,
+		MinBlockDuration: int64(time.Duration(opts.MinBlockDuration) / time.Millisecond),
+		MaxBlockDuration: int64(time.Duration(opts.MaxBlockDuration) / time.Millisecond),
+		MaxExemplars:     opts.MaxExemplars,
 	}
 }
 
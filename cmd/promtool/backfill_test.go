@@ -540,7 +540,7 @@ after_eof 1 2
 			require.NoError(t, os.RemoveAll(outputDir))
 		}()
 
-		err = backfill(test.MaxSamplesInAppender, []byte(test.ToParse), outputDir, false)
+		err = backfill(test.MaxSamplesInAppender, []byte(test.ToParse), outputDir, false, 0)
 
 		if !test.IsOk {
 			require.Error(t, err, test.Description)
@@ -557,3 +557,17 @@ after_eof 1 2
 		testBlocks(t, db, test.Expected.MinTime, test.Expected.MaxTime, test.Expected.Samples, test.Expected.NumBlocks)
 	}
 }
+
+func TestGetCompatibleBlockDuration(t *testing.T) {
+	for _, tc := range []struct {
+		maxBlockDuration, expected int64
+	}{
+		{0, tsdb.DefaultBlockDuration},
+		{tsdb.DefaultBlockDuration, tsdb.DefaultBlockDuration},
+		{5 * tsdb.DefaultBlockDuration, 3 * tsdb.DefaultBlockDuration},
+		{27 * tsdb.DefaultBlockDuration, 27 * tsdb.DefaultBlockDuration},
+		{1000000 * tsdb.DefaultBlockDuration, 19683 * tsdb.DefaultBlockDuration},
+	} {
+		require.Equal(t, tc.expected, getCompatibleBlockDuration(tc.maxBlockDuration))
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/adolli/prometheus/pkg/rulefmt"
+	"github.com/adolli/prometheus/promql/parser"
+)
+
+// LintRules parses the rule files, normalizes the PromQL expressions and key
+// order, and warns on common anti-patterns. The normalized files are printed
+// to standard output, or rewritten in place when inPlace is set.
+func LintRules(inPlace bool, files ...string) int {
+	failed := false
+
+	for _, f := range files {
+		fmt.Fprintln(os.Stderr, "Linting", f)
+		rgs, errs := rulefmt.ParseFile(f)
+		if errs != nil {
+			fmt.Fprintln(os.Stderr, "  FAILED:")
+			for _, e := range errs {
+				fmt.Fprintln(os.Stderr, e.Error())
+			}
+			failed = true
+			continue
+		}
+
+		for gi := range rgs.Groups {
+			g := &rgs.Groups[gi]
+			for ri := range g.Rules {
+				r := &g.Rules[ri]
+				for _, w := range lintRule(r) {
+					fmt.Fprintf(os.Stderr, "  WARNING: group %q, rule %q: %s\n", g.Name, ruleName(r), w)
+				}
+				expr, err := parser.ParseExpr(r.Expr.Value)
+				if err != nil {
+					// ParseFile has already validated the expression.
+					continue
+				}
+				r.Expr = yaml.Node{Kind: yaml.ScalarNode, Value: expr.String()}
+			}
+		}
+
+		b, err := yaml.Marshal(rgs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "  FAILED:", err)
+			failed = true
+			continue
+		}
+
+		if inPlace {
+			fi, err := os.Stat(f)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "  FAILED:", err)
+				failed = true
+				continue
+			}
+			if err := ioutil.WriteFile(f, b, fi.Mode().Perm()); err != nil {
+				fmt.Fprintln(os.Stderr, "  FAILED:", err)
+				failed = true
+				continue
+			}
+		} else {
+			fmt.Printf("%s", b)
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func ruleName(r *rulefmt.RuleNode) string {
+	if r.Alert.Value != "" {
+		return r.Alert.Value
+	}
+	return r.Record.Value
+}
+
+// lintRule returns warnings for common rule anti-patterns.
+func lintRule(r *rulefmt.RuleNode) []string {
+	var warnings []string
+
+	if r.Alert.Value != "" && r.For == 0 {
+		warnings = append(warnings, "alerting rule without 'for' duration will fire on a single breach")
+	}
+
+	expr, err := parser.ParseExpr(r.Expr.Value)
+	if err != nil {
+		return warnings
+	}
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		agg, ok := node.(*parser.AggregateExpr)
+		if !ok {
+			return nil
+		}
+		dropsJob := false
+		if agg.Without {
+			for _, g := range agg.Grouping {
+				if g == "job" {
+					dropsJob = true
+					break
+				}
+			}
+		} else {
+			dropsJob = true
+			for _, g := range agg.Grouping {
+				if g == "job" {
+					dropsJob = false
+					break
+				}
+			}
+		}
+		if dropsJob {
+			warnings = append(warnings, fmt.Sprintf("aggregation %q drops the 'job' label", agg.String()))
+		}
+		return nil
+	})
+
+	return warnings
+}
@@ -28,6 +28,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-kit/kit/log"
 	"github.com/google/pprof/profile"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/api"
@@ -41,9 +42,11 @@ import (
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/adolli/prometheus/config"
+	"github.com/adolli/prometheus/discovery"
 	"github.com/adolli/prometheus/discovery/file"
 	_ "github.com/adolli/prometheus/discovery/install" // Register service discovery implementations.
 	"github.com/adolli/prometheus/discovery/kubernetes"
+	"github.com/adolli/prometheus/discovery/targetgroup"
 	"github.com/adolli/prometheus/pkg/rulefmt"
 )
 
@@ -59,6 +62,8 @@ func main() {
 		"config-files",
 		"The config files to check.",
 	).Required().ExistingFiles()
+	checkConfigSDDryRun := checkConfigCmd.Flag("dry-run-sd", "Run each service discovery configuration once and print a sample of the discovered targets.").Bool()
+	checkConfigSDTimeout := checkConfigCmd.Flag("sd-timeout", "Timeout for each service discovery dry-run.").Default("30s").Duration()
 
 	checkWebConfigCmd := checkCmd.Command("web-config", "Check if the web config files are valid or not.")
 	webConfigFiles := checkWebConfigCmd.Arg(
@@ -110,6 +115,31 @@ func main() {
 	queryLabelsBegin := queryLabelsCmd.Flag("start", "Start time (RFC3339 or Unix timestamp).").String()
 	queryLabelsEnd := queryLabelsCmd.Flag("end", "End time (RFC3339 or Unix timestamp).").String()
 
+	queryBlocksCmd := queryCmd.Command("blocks", "Run a PromQL query directly against a TSDB data directory, without a running server.")
+	queryBlocksPath := queryBlocksCmd.Arg("db path", "Database path.").Required().String()
+	queryBlocksExpr := queryBlocksCmd.Arg("expr", "PromQL query expression.").Required().String()
+	queryBlocksTime := queryBlocksCmd.Flag("time", "Instant query evaluation time (RFC3339 or Unix timestamp).").String()
+	queryBlocksBegin := queryBlocksCmd.Flag("start", "Range query start time (RFC3339 or Unix timestamp).").String()
+	queryBlocksEnd := queryBlocksCmd.Flag("end", "Range query end time (RFC3339 or Unix timestamp).").String()
+	queryBlocksStep := queryBlocksCmd.Flag("step", "Range query step size (duration).").Duration()
+
+	lintCmd := app.Command("lint", "Lint and normalize resources.")
+	lintRulesCmd := lintCmd.Command("rules", "Lint rule files, normalize their PromQL expressions and key order, and optionally rewrite them in place.")
+	lintRulesInPlace := lintRulesCmd.Flag("in-place", "Rewrite the rule files in place instead of printing to standard output.").Short('i').Bool()
+	lintRulesFiles := lintRulesCmd.Arg(
+		"rule-files",
+		"The rule files to lint.",
+	).Required().ExistingFiles()
+
+	pushCmd := app.Command("push", "Push to a Prometheus server.")
+	pushMetricsCmd := pushCmd.Command("metrics", "Push samples from an input file to a remote write endpoint.")
+	pushMetricsURL := pushMetricsCmd.Arg("remote-write-url", "Remote write endpoint to send the samples to.").Required().URL()
+	pushMetricsFile := pushMetricsCmd.Arg("input file", "File to read samples from.").Required().ExistingFile()
+	pushMetricsFormat := pushMetricsCmd.Flag("format", "Format of the input file.").Default("openmetrics").Enum("openmetrics", "csv")
+	pushMetricsBatchSize := pushMetricsCmd.Flag("batch-size", "Maximum number of samples per remote write request.").Default("10000").Int()
+	pushMetricsTimeout := pushMetricsCmd.Flag("timeout", "Timeout for each remote write request.").Default("30s").Duration()
+	pushMetricsHeaders := pushMetricsCmd.Flag("header", "Extra headers to send to server.").StringMap()
+
 	testCmd := app.Command("test", "Unit testing.")
 	testRulesCmd := testCmd.Command("rules", "Unit tests for rules.")
 	testRulesFiles := testRulesCmd.Arg(
@@ -131,6 +161,8 @@ func main() {
 	analyzePath := tsdbAnalyzeCmd.Arg("db path", "Database path (default is "+defaultDBPath+").").Default(defaultDBPath).String()
 	analyzeBlockID := tsdbAnalyzeCmd.Arg("block id", "Block to analyze (default is the last block).").String()
 	analyzeLimit := tsdbAnalyzeCmd.Flag("limit", "How many items to show in each list.").Default("20").Int()
+	analyzeExtended := tsdbAnalyzeCmd.Flag("extended", "Run extended analysis with chunk-level statistics. Analyzes all blocks unless a block id is given.").Bool()
+	analyzeFormat := tsdbAnalyzeCmd.Flag("format", "Output format of the extended analysis.").Default("text").Enum("text", "json")
 
 	tsdbListCmd := tsdbCmd.Command("list", "List tsdb blocks.")
 	listHumanReadable := tsdbListCmd.Flag("human-readable", "Print human readable values.").Short('r').Bool()
@@ -140,13 +172,15 @@ func main() {
 	dumpPath := tsdbDumpCmd.Arg("db path", "Database path (default is "+defaultDBPath+").").Default(defaultDBPath).String()
 	dumpMinTime := tsdbDumpCmd.Flag("min-time", "Minimum timestamp to dump.").Default(strconv.FormatInt(math.MinInt64, 10)).Int64()
 	dumpMaxTime := tsdbDumpCmd.Flag("max-time", "Maximum timestamp to dump.").Default(strconv.FormatInt(math.MaxInt64, 10)).Int64()
+	dumpMatch := tsdbDumpCmd.Flag("match", "Series selector. Can be specified multiple times.").Default("{__name__=~'(?s:.*)'}").Strings()
+	dumpFormat := tsdbDumpCmd.Flag("format", "Output format of the dump.").Default("dump").Enum("dump", "openmetrics", "json", "csv")
 
 	importCmd := tsdbCmd.Command("create-blocks-from", "[Experimental] Import samples from input and produce TSDB blocks. Please refer to the storage docs for more details.")
 	importHumanReadable := importCmd.Flag("human-readable", "Print human readable values.").Short('r').Bool()
 	openMetricsImportCmd := importCmd.Command("openmetrics", "Import samples from OpenMetrics input and produce TSDB blocks. Please refer to the storage docs for more details.")
-	// TODO(aSquare14): add flag to set default block duration
 	importFilePath := openMetricsImportCmd.Arg("input file", "OpenMetrics file to read samples from.").Required().String()
 	importDBPath := openMetricsImportCmd.Arg("output directory", "Output directory for generated blocks.").Default(defaultDBPath).String()
+	importMaxBlockDuration := openMetricsImportCmd.Flag("max-block-duration", "Maximum duration created blocks may span. Anything less than 2h is ignored.").Hidden().PlaceHolder("<duration>").Default("0s").Duration()
 
 	parsedCmd := kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -160,7 +194,7 @@ func main() {
 
 	switch parsedCmd {
 	case checkConfigCmd.FullCommand():
-		os.Exit(CheckConfig(*configFiles...))
+		os.Exit(CheckConfig(*checkConfigSDDryRun, *checkConfigSDTimeout, *configFiles...))
 
 	case checkWebConfigCmd.FullCommand():
 		os.Exit(CheckWebConfig(*webConfigFiles...))
@@ -192,6 +226,15 @@ func main() {
 	case queryLabelsCmd.FullCommand():
 		os.Exit(QueryLabels(*queryLabelsServer, *queryLabelsName, *queryLabelsBegin, *queryLabelsEnd, p))
 
+	case queryBlocksCmd.FullCommand():
+		os.Exit(checkErr(queryBlocks(*queryBlocksPath, *queryBlocksExpr, *queryBlocksTime, *queryBlocksBegin, *queryBlocksEnd, *queryBlocksStep, *queryCmdFmt == "json")))
+
+	case lintRulesCmd.FullCommand():
+		os.Exit(LintRules(*lintRulesInPlace, *lintRulesFiles...))
+
+	case pushMetricsCmd.FullCommand():
+		os.Exit(PushMetrics(*pushMetricsURL, *pushMetricsFile, *pushMetricsFormat, *pushMetricsBatchSize, *pushMetricsTimeout, *pushMetricsHeaders))
+
 	case testRulesCmd.FullCommand():
 		os.Exit(RulesUnitTest(*testRulesFiles...))
 
@@ -199,25 +242,27 @@ func main() {
 		os.Exit(checkErr(benchmarkWrite(*benchWriteOutPath, *benchSamplesFile, *benchWriteNumMetrics, *benchWriteNumScrapes)))
 
 	case tsdbAnalyzeCmd.FullCommand():
+		if *analyzeExtended {
+			os.Exit(checkErr(analyzeBlocksExtended(*analyzePath, *analyzeBlockID, *analyzeLimit, *analyzeFormat == "json")))
+		}
 		os.Exit(checkErr(analyzeBlock(*analyzePath, *analyzeBlockID, *analyzeLimit)))
 
 	case tsdbListCmd.FullCommand():
 		os.Exit(checkErr(listBlocks(*listPath, *listHumanReadable)))
 
 	case tsdbDumpCmd.FullCommand():
-		os.Exit(checkErr(dumpSamples(*dumpPath, *dumpMinTime, *dumpMaxTime)))
-	//TODO(aSquare14): Work on adding support for custom block size.
+		os.Exit(checkErr(dumpSamples(*dumpPath, *dumpMinTime, *dumpMaxTime, *dumpMatch, *dumpFormat)))
 	case openMetricsImportCmd.FullCommand():
-		os.Exit(backfillOpenMetrics(*importFilePath, *importDBPath, *importHumanReadable))
+		os.Exit(backfillOpenMetrics(*importFilePath, *importDBPath, *importHumanReadable, *importMaxBlockDuration))
 	}
 }
 
 // CheckConfig validates configuration files.
-func CheckConfig(files ...string) int {
+func CheckConfig(sdDryRun bool, sdTimeout time.Duration, files ...string) int {
 	failed := false
 
 	for _, f := range files {
-		ruleFiles, err := checkConfig(f)
+		ruleFiles, err := checkConfig(f, sdDryRun, sdTimeout)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "  FAILED:", err)
 			failed = true
@@ -272,7 +317,33 @@ func checkFileExists(fn string) error {
 	return err
 }
 
-func checkConfig(filename string) ([]string, error) {
+// checkFileReadable errors if the file exists but cannot be opened for reading.
+func checkFileReadable(fn string) error {
+	if fn == "" {
+		return nil
+	}
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// checkSecretFilePerms warns when a credentials file is accessible to other users.
+func checkSecretFilePerms(fn string) {
+	if fn == "" {
+		return
+	}
+	fi, err := os.Stat(fn)
+	if err != nil {
+		return
+	}
+	if mode := fi.Mode().Perm(); mode&0077 != 0 {
+		fmt.Printf("  WARNING: secret file %q has permissions %v, should not be readable by other users\n", fn, mode)
+	}
+}
+
+func checkConfig(filename string, sdDryRun bool, sdTimeout time.Duration) ([]string, error) {
 	fmt.Println("Checking", filename)
 
 	cfg, err := config.LoadFile(filename)
@@ -298,10 +369,17 @@ func checkConfig(filename string) ([]string, error) {
 		ruleFiles = append(ruleFiles, rfs...)
 	}
 
+	for _, rf := range ruleFiles {
+		if err := checkFileReadable(rf); err != nil {
+			return nil, errors.Wrapf(err, "error reading rule file %q", rf)
+		}
+	}
+
 	for _, scfg := range cfg.ScrapeConfigs {
 		if err := checkFileExists(scfg.HTTPClientConfig.BearerTokenFile); err != nil {
 			return nil, errors.Wrapf(err, "error checking bearer token file %q", scfg.HTTPClientConfig.BearerTokenFile)
 		}
+		checkSecretFilePerms(scfg.HTTPClientConfig.BearerTokenFile)
 
 		if err := checkTLSConfig(scfg.HTTPClientConfig.TLSConfig); err != nil {
 			return nil, err
@@ -328,11 +406,63 @@ func checkConfig(filename string) ([]string, error) {
 				}
 			}
 		}
+
+		if sdDryRun {
+			if err := checkSDDryRun(scfg, sdTimeout); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return ruleFiles, nil
 }
 
+// checkSDDryRun instantiates every service discovery mechanism of a scrape
+// config and waits for the first set of discovered target groups. This
+// validates that the configured credentials and permissions are usable.
+func checkSDDryRun(scfg *config.ScrapeConfig, timeout time.Duration) error {
+	const maxSampleTargets = 10
+
+	for _, c := range scfg.ServiceDiscoveryConfigs {
+		if _, ok := c.(discovery.StaticConfig); ok {
+			continue
+		}
+		d, err := c.NewDiscoverer(discovery.DiscovererOptions{Logger: log.NewNopLogger()})
+		if err != nil {
+			return errors.Wrapf(err, "creating %q discoverer for scrape job %q", c.Name(), scfg.JobName)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		up := make(chan []*targetgroup.Group)
+		go d.Run(ctx, up)
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			return errors.Errorf("%q discovery for scrape job %q did not return any target group within %s", c.Name(), scfg.JobName, timeout)
+		case tgs := <-up:
+			cancel()
+			var targets int
+			for _, tg := range tgs {
+				targets += len(tg.Targets)
+			}
+			fmt.Printf("  SUCCESS: %q discovery for scrape job %q returned %d targets\n", c.Name(), scfg.JobName, targets)
+			printed := 0
+		sample:
+			for _, tg := range tgs {
+				for _, t := range tg.Targets {
+					if printed == maxSampleTargets {
+						break sample
+					}
+					fmt.Printf("    %v\n", t)
+					printed++
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func checkTLSConfig(tlsConfig config_util.TLSConfig) error {
 	if err := checkFileExists(tlsConfig.CertFile); err != nil {
 		return errors.Wrapf(err, "error checking client cert file %q", tlsConfig.CertFile)
@@ -340,6 +470,7 @@ func checkTLSConfig(tlsConfig config_util.TLSConfig) error {
 	if err := checkFileExists(tlsConfig.KeyFile); err != nil {
 		return errors.Wrapf(err, "error checking client key file %q", tlsConfig.KeyFile)
 	}
+	checkSecretFilePerms(tlsConfig.KeyFile)
 
 	if len(tlsConfig.CertFile) > 0 && len(tlsConfig.KeyFile) == 0 {
 		return errors.Errorf("client cert file %q specified without client key file", tlsConfig.CertFile)
@@ -0,0 +1,212 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	config_util "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/adolli/prometheus/pkg/labels"
+	"github.com/adolli/prometheus/pkg/textparse"
+	"github.com/adolli/prometheus/prompb"
+	"github.com/adolli/prometheus/promql/parser"
+	"github.com/adolli/prometheus/storage/remote"
+)
+
+// pushSample is a single sample read from the input file.
+type pushSample struct {
+	lset labels.Labels
+	t    int64
+	v    float64
+}
+
+// PushMetrics reads samples from an input file and sends them to a remote
+// write endpoint in batches.
+func PushMetrics(rwURL *url.URL, inputFile, format string, batchSize int, timeout time.Duration, headers map[string]string) int {
+	b, err := ioutil.ReadFile(inputFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error reading input file:", err)
+		return 1
+	}
+
+	var samples []pushSample
+	switch format {
+	case "openmetrics":
+		samples, err = parseOpenMetricsSamples(b)
+	case "csv":
+		samples, err = parseCSVSamples(b)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error parsing input file:", err)
+		return 1
+	}
+	if len(samples) == 0 {
+		fmt.Fprintln(os.Stderr, "no samples found in input file")
+		return 1
+	}
+
+	client, err := remote.NewWriteClient("promtool", &remote.ClientConfig{
+		URL:     &config_util.URL{URL: rwURL},
+		Timeout: model.Duration(timeout),
+		Headers: headers,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error creating write client:", err)
+		return 1
+	}
+
+	pushed := 0
+	for len(samples) > 0 {
+		n := batchSize
+		if n > len(samples) {
+			n = len(samples)
+		}
+		req, err := buildPushRequest(samples[:n])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error building write request:", err)
+			return 1
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err = client.Store(ctx, req)
+		cancel()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error sending write request:", err)
+			return 1
+		}
+
+		pushed += n
+		samples = samples[n:]
+		fmt.Printf("  pushed %d samples, %d remaining\n", pushed, len(samples))
+	}
+
+	fmt.Println("  SUCCESS:", pushed, "samples pushed")
+	return 0
+}
+
+// buildPushRequest converts the samples to a compressed remote write request.
+func buildPushRequest(samples []pushSample) ([]byte, error) {
+	ts := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		lbls := make([]prompb.Label, 0, len(s.lset))
+		for _, l := range s.lset {
+			lbls = append(lbls, prompb.Label{Name: l.Name, Value: l.Value})
+		}
+		ts = append(ts, prompb.TimeSeries{
+			Labels:  lbls,
+			Samples: []prompb.Sample{{Value: s.v, Timestamp: s.t}},
+		})
+	}
+
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: ts})
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+// parseOpenMetricsSamples reads samples from OpenMetrics input. Samples
+// without a timestamp get the current time assigned.
+func parseOpenMetricsSamples(input []byte) ([]pushSample, error) {
+	var samples []pushSample
+
+	now := timestampMs(time.Now())
+	p := textparse.NewOpenMetricsParser(input)
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "parse")
+		}
+		if e != textparse.EntrySeries {
+			continue
+		}
+
+		_, ts, v := p.Series()
+		l := labels.Labels{}
+		p.Metric(&l)
+
+		t := now
+		if ts != nil {
+			t = *ts
+		}
+		samples = append(samples, pushSample{lset: l, t: t, v: v})
+	}
+	return samples, nil
+}
+
+// parseCSVSamples reads samples from CSV input. Each record consists of a
+// series in the usual notation, a value and an optional timestamp in
+// milliseconds, e.g.
+//
+//	"up{job=""prometheus""}",1,1613758203000
+//
+// Series containing commas must be quoted following the usual CSV rules.
+func parseCSVSamples(input []byte) ([]pushSample, error) {
+	var samples []pushSample
+
+	now := timestampMs(time.Now())
+	r := csv.NewReader(bytes.NewReader(input))
+	r.FieldsPerRecord = -1
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, errors.Errorf("expected 2 or 3 fields, got %d: %v", len(record), record)
+		}
+
+		l, err := parser.ParseMetric(record[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "series %q", record[0])
+		}
+		v, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value %q", record[1])
+		}
+		t := now
+		if len(record) == 3 {
+			t, err = strconv.ParseInt(record[2], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "timestamp %q", record[2])
+			}
+		}
+		samples = append(samples, pushSample{lset: l, t: t, v: v})
+	}
+	return samples, nil
+}
+
+func timestampMs(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
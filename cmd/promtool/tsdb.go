@@ -16,9 +16,12 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -35,6 +38,9 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/adolli/prometheus/pkg/labels"
+	"github.com/adolli/prometheus/promql"
+	"github.com/adolli/prometheus/promql/parser"
+	"github.com/adolli/prometheus/storage"
 	"github.com/adolli/prometheus/tsdb"
 	"github.com/adolli/prometheus/tsdb/chunks"
 	tsdb_errors "github.com/adolli/prometheus/tsdb/errors"
@@ -564,7 +570,210 @@ func analyzeBlock(path, blockID string, limit int) error {
 	return nil
 }
 
-func dumpSamples(path string, mint, maxt int64) (err error) {
+// Bucket upper bounds used by the extended analysis. The last bucket of each
+// set catches everything above the previous bound.
+var (
+	chunkSizeBuckets       = []int64{64, 128, 256, 512, 1024, 2048, 4096, math.MaxInt64}
+	samplesPerChunkBuckets = []int64{30, 60, 90, 120, 240, math.MaxInt64}
+)
+
+type analysisBucket struct {
+	UpperBound int64  `json:"le"`
+	Count      uint64 `json:"count"`
+}
+
+type seriesIndexSize struct {
+	Series string `json:"series"`
+	Bytes  uint64 `json:"bytes"`
+}
+
+type extendedBlockStats struct {
+	BlockID               string            `json:"blockID"`
+	ChunkCount            uint64            `json:"chunkCount"`
+	ChunkSizeDistribution []analysisBucket  `json:"chunkSizeDistribution"`
+	SamplesPerChunk       []analysisBucket  `json:"samplesPerChunk"`
+	LabelPairEntropyBits  float64           `json:"labelPairEntropyBits"`
+	LargestIndexSeries    []seriesIndexSize `json:"largestIndexSeries"`
+}
+
+func newBuckets(bounds []int64) []analysisBucket {
+	buckets := make([]analysisBucket, 0, len(bounds))
+	for _, le := range bounds {
+		buckets = append(buckets, analysisBucket{UpperBound: le})
+	}
+	return buckets
+}
+
+func observeBucket(buckets []analysisBucket, v int64) {
+	for i := range buckets {
+		if v <= buckets[i].UpperBound {
+			buckets[i].Count++
+			return
+		}
+	}
+}
+
+func analyzeBlockExtended(block tsdb.BlockReader, limit int) (*extendedBlockStats, error) {
+	stats := &extendedBlockStats{
+		BlockID:               block.Meta().ULID.String(),
+		ChunkSizeDistribution: newBuckets(chunkSizeBuckets),
+		SamplesPerChunk:       newBuckets(samplesPerChunkBuckets),
+	}
+
+	ir, err := block.Index()
+	if err != nil {
+		return nil, err
+	}
+	defer ir.Close()
+
+	cr, err := block.Chunks()
+	if err != nil {
+		return nil, err
+	}
+	defer cr.Close()
+
+	p, err := ir.Postings("", "") // The special all key.
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		labelpairsCount = map[string]uint64{}
+		entries         uint64
+		lbls            = labels.Labels{}
+		chks            = []chunks.Meta{}
+	)
+	for p.Next() {
+		if err := ir.Series(p.At(), &lbls, &chks); err != nil {
+			return nil, err
+		}
+
+		// Approximate contribution of this series to the index: the label
+		// symbols it references plus a fixed overhead per chunk meta.
+		var indexSize uint64
+		for _, lbl := range lbls {
+			key := lbl.Name + "=" + lbl.Value
+			labelpairsCount[key]++
+			entries++
+			indexSize += uint64(len(lbl.Name) + len(lbl.Value))
+		}
+		indexSize += uint64(len(chks)) * 16
+
+		stats.LargestIndexSeries = append(stats.LargestIndexSeries, seriesIndexSize{Series: lbls.String(), Bytes: indexSize})
+		if len(stats.LargestIndexSeries) > 4*limit {
+			sort.Slice(stats.LargestIndexSeries, func(i, j int) bool {
+				return stats.LargestIndexSeries[i].Bytes > stats.LargestIndexSeries[j].Bytes
+			})
+			stats.LargestIndexSeries = stats.LargestIndexSeries[:limit]
+		}
+
+		for _, meta := range chks {
+			chk, err := cr.Chunk(meta.Ref)
+			if err != nil {
+				return nil, err
+			}
+			stats.ChunkCount++
+			observeBucket(stats.ChunkSizeDistribution, int64(len(chk.Bytes())))
+			observeBucket(stats.SamplesPerChunk, int64(chk.NumSamples()))
+		}
+	}
+	if p.Err() != nil {
+		return nil, p.Err()
+	}
+
+	sort.Slice(stats.LargestIndexSeries, func(i, j int) bool {
+		return stats.LargestIndexSeries[i].Bytes > stats.LargestIndexSeries[j].Bytes
+	})
+	if len(stats.LargestIndexSeries) > limit {
+		stats.LargestIndexSeries = stats.LargestIndexSeries[:limit]
+	}
+
+	// Shannon entropy of the label pair distribution. The closer the value is
+	// to log2(pairs), the more uniformly the index entries are spread.
+	for _, c := range labelpairsCount {
+		p := float64(c) / float64(entries)
+		stats.LabelPairEntropyBits -= p * math.Log2(p)
+	}
+
+	return stats, nil
+}
+
+func printExtendedBlockStats(stats *extendedBlockStats) {
+	fmt.Printf("Block ID: %s\n", stats.BlockID)
+	fmt.Printf("Chunks: %d\n", stats.ChunkCount)
+
+	printBuckets := func(buckets []analysisBucket) {
+		for _, b := range buckets {
+			if b.UpperBound == math.MaxInt64 {
+				fmt.Printf("> %d: %d\n", buckets[len(buckets)-2].UpperBound, b.Count)
+				continue
+			}
+			fmt.Printf("<= %d: %d\n", b.UpperBound, b.Count)
+		}
+	}
+
+	fmt.Printf("\nChunk size distribution (bytes):\n")
+	printBuckets(stats.ChunkSizeDistribution)
+
+	fmt.Printf("\nSamples per chunk:\n")
+	printBuckets(stats.SamplesPerChunk)
+
+	fmt.Printf("\nLabel pair entropy: %.2f bits\n", stats.LabelPairEntropyBits)
+
+	fmt.Printf("\nSeries contributing most to index size:\n")
+	for _, s := range stats.LargestIndexSeries {
+		fmt.Printf("%d %s\n", s.Bytes, s.Series)
+	}
+}
+
+func analyzeBlocksExtended(path, blockID string, limit int, asJSON bool) (err error) {
+	db, err := tsdb.OpenDBReadOnly(path, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = tsdb_errors.NewMulti(err, db.Close()).Err()
+	}()
+
+	blocks, err := db.Blocks()
+	if err != nil {
+		return err
+	}
+	selected := make([]tsdb.BlockReader, 0, len(blocks))
+	for _, b := range blocks {
+		if blockID == "" || b.Meta().ULID.String() == blockID {
+			selected = append(selected, b)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("block %s not found", blockID)
+	}
+
+	allStats := make([]*extendedBlockStats, 0, len(selected))
+	for _, b := range selected {
+		stats, err := analyzeBlockExtended(b, limit)
+		if err != nil {
+			return err
+		}
+		allStats = append(allStats, stats)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(allStats)
+	}
+
+	for i, stats := range allStats {
+		if i > 0 {
+			fmt.Println()
+		}
+		printExtendedBlockStats(stats)
+	}
+	return nil
+}
+
+func dumpSamples(path string, mint, maxt int64, match []string, format string) (err error) {
 	db, err := tsdb.OpenDBReadOnly(path, nil)
 	if err != nil {
 		return err
@@ -578,7 +787,35 @@ func dumpSamples(path string, mint, maxt int64) (err error) {
 	}
 	defer q.Close()
 
-	ss := q.Select(false, nil, labels.MustNewMatcher(labels.MatchRegexp, "", ".*"))
+	matcherSets := make([][]*labels.Matcher, 0, len(match))
+	for _, s := range match {
+		matchers, err := parser.ParseMetricSelector(s)
+		if err != nil {
+			return errors.Wrapf(err, "parsing series selector %q", s)
+		}
+		matcherSets = append(matcherSets, matchers)
+	}
+
+	var ss storage.SeriesSet
+	if len(matcherSets) == 1 {
+		ss = q.Select(false, nil, matcherSets[0]...)
+	} else {
+		sets := make([]storage.SeriesSet, 0, len(matcherSets))
+		for _, mset := range matcherSets {
+			sets = append(sets, q.Select(true, nil, mset...))
+		}
+		ss = storage.NewMergeSeriesSet(sets, storage.ChainedSeriesMerge)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer func() {
+		err = tsdb_errors.NewMulti(err, w.Flush()).Err()
+	}()
+
+	dump, err := newDumpWriter(w, format)
+	if err != nil {
+		return err
+	}
 
 	for ss.Next() {
 		series := ss.At()
@@ -586,13 +823,19 @@ func dumpSamples(path string, mint, maxt int64) (err error) {
 		it := series.Iterator()
 		for it.Next() {
 			ts, val := it.At()
-			fmt.Printf("%s %g %d\n", lbs, val, ts)
+			if err := dump.writeSample(lbs, ts, val); err != nil {
+				return err
+			}
 		}
 		if it.Err() != nil {
 			return ss.Err()
 		}
 	}
 
+	if err := dump.close(); err != nil {
+		return err
+	}
+
 	if ws := ss.Warnings(); len(ws) > 0 {
 		return tsdb_errors.NewMulti(ws...).Err()
 	}
@@ -603,6 +846,189 @@ func dumpSamples(path string, mint, maxt int64) (err error) {
 	return nil
 }
 
+// dumpWriter writes samples to an output stream in one of the supported
+// dump formats.
+type dumpWriter interface {
+	writeSample(lbs labels.Labels, ts int64, val float64) error
+	close() error
+}
+
+func newDumpWriter(w io.Writer, format string) (dumpWriter, error) {
+	switch format {
+	case "dump":
+		return &plainDumpWriter{w: w}, nil
+	case "openmetrics":
+		return &openMetricsDumpWriter{w: w}, nil
+	case "json":
+		return &jsonDumpWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return &csvDumpWriter{w: csv.NewWriter(w)}, nil
+	}
+	return nil, errors.Errorf("unknown dump format %q", format)
+}
+
+type plainDumpWriter struct {
+	w io.Writer
+}
+
+func (d *plainDumpWriter) writeSample(lbs labels.Labels, ts int64, val float64) error {
+	_, err := fmt.Fprintf(d.w, "%s %g %d\n", lbs, val, ts)
+	return err
+}
+
+func (d *plainDumpWriter) close() error { return nil }
+
+type openMetricsDumpWriter struct {
+	w io.Writer
+}
+
+func (d *openMetricsDumpWriter) writeSample(lbs labels.Labels, ts int64, val float64) error {
+	_, err := fmt.Fprintf(d.w, "%s %g %g\n", openMetricsSeries(lbs), val, float64(ts)/1000)
+	return err
+}
+
+func (d *openMetricsDumpWriter) close() error {
+	_, err := fmt.Fprintln(d.w, "# EOF")
+	return err
+}
+
+// openMetricsSeries formats the labels with the metric name in front of the
+// label matchers instead of as a __name__ matcher.
+func openMetricsSeries(lbs labels.Labels) string {
+	var b strings.Builder
+	b.WriteString(lbs.Get(labels.MetricName))
+	b.WriteByte('{')
+	i := 0
+	for _, l := range lbs {
+		if l.Name == labels.MetricName {
+			continue
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l.Value))
+		i++
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+type jsonDumpWriter struct {
+	enc *json.Encoder
+}
+
+func (d *jsonDumpWriter) writeSample(lbs labels.Labels, ts int64, val float64) error {
+	return d.enc.Encode(struct {
+		Labels    labels.Labels `json:"labels"`
+		Value     float64       `json:"value"`
+		Timestamp int64         `json:"timestamp"`
+	}{lbs, val, ts})
+}
+
+func (d *jsonDumpWriter) close() error { return nil }
+
+type csvDumpWriter struct {
+	w *csv.Writer
+}
+
+func (d *csvDumpWriter) writeSample(lbs labels.Labels, ts int64, val float64) error {
+	return d.w.Write([]string{
+		lbs.String(),
+		strconv.FormatFloat(val, 'g', -1, 64),
+		strconv.FormatInt(ts, 10),
+	})
+}
+
+func (d *csvDumpWriter) close() error {
+	d.w.Flush()
+	return d.w.Error()
+}
+
+// queryBlocks evaluates a PromQL expression directly against a TSDB data
+// directory, opened read-only. It runs a range query if any of start, end or
+// step is given and an instant query otherwise.
+func queryBlocks(path, queryExpr, evalTime, start, end string, step time.Duration, asJSON bool) (err error) {
+	db, err := tsdb.OpenDBReadOnly(path, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = tsdb_errors.NewMulti(err, db.Close()).Err()
+	}()
+
+	engine := promql.NewEngine(promql.EngineOpts{
+		Logger:           log.NewNopLogger(),
+		MaxSamples:       50000000,
+		Timeout:          2 * time.Minute,
+		EnableAtModifier: true,
+	})
+
+	var q promql.Query
+	if start == "" && end == "" && step == 0 {
+		eTime := time.Now()
+		if evalTime != "" {
+			eTime, err = parseTime(evalTime)
+			if err != nil {
+				return errors.Wrap(err, "parsing evaluation time")
+			}
+		}
+		q, err = engine.NewInstantQuery(db, queryExpr, eTime)
+		if err != nil {
+			return err
+		}
+	} else {
+		if evalTime != "" {
+			return errors.New("--time cannot be combined with --start, --end or --step")
+		}
+		etime := time.Now()
+		if end != "" {
+			etime, err = parseTime(end)
+			if err != nil {
+				return errors.Wrap(err, "parsing end time")
+			}
+		}
+		stime := etime.Add(-5 * time.Minute)
+		if start != "" {
+			stime, err = parseTime(start)
+			if err != nil {
+				return errors.Wrap(err, "parsing start time")
+			}
+		}
+		if !stime.Before(etime) {
+			return errors.New("start time is not before end time")
+		}
+		if step == 0 {
+			resolution := math.Max(math.Floor(etime.Sub(stime).Seconds()/250), 1)
+			// Convert seconds to nanoseconds such that time.Duration parses correctly.
+			step = time.Duration(resolution) * time.Second
+		}
+		q, err = engine.NewRangeQuery(db, queryExpr, stime, etime, step)
+		if err != nil {
+			return err
+		}
+	}
+	defer q.Close()
+
+	res := q.Exec(context.Background())
+	if res.Err != nil {
+		return res.Err
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(struct {
+			ResultType parser.ValueType `json:"resultType"`
+			Result     parser.Value     `json:"result"`
+		}{res.Value.Type(), res.Value})
+	}
+	fmt.Println(res.Value.String())
+	return nil
+}
+
 func checkErr(err error) int {
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -611,7 +1037,7 @@ func checkErr(err error) int {
 	return 0
 }
 
-func backfillOpenMetrics(path string, outputDir string, humanReadable bool) int {
+func backfillOpenMetrics(path string, outputDir string, humanReadable bool, maxBlockDuration time.Duration) int {
 	inputFile, err := fileutil.OpenMmapFile(path)
 	if err != nil {
 		return checkErr(err)
@@ -622,5 +1048,5 @@ func backfillOpenMetrics(path string, outputDir string, humanReadable bool) int
 		return checkErr(errors.Wrap(err, "create output dir"))
 	}
 
-	return checkErr(backfill(5000, inputFile.Bytes(), outputDir, humanReadable))
+	return checkErr(backfill(5000, inputFile.Bytes(), outputDir, humanReadable, maxBlockDuration))
 }
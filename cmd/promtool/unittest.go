@@ -30,11 +30,13 @@ import (
 	"github.com/prometheus/common/model"
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/promql"
 	"github.com/adolli/prometheus/promql/parser"
 	"github.com/adolli/prometheus/rules"
 	"github.com/adolli/prometheus/storage"
+	"github.com/adolli/prometheus/tsdb"
 )
 
 // RulesUnitTest does unit testing of rules based on the unit testing files provided.
@@ -141,12 +143,14 @@ func resolveAndGlobFilepaths(baseDir string, utf *unitTestFile) error {
 
 // testGroup is a group of input series and tests associated with it.
 type testGroup struct {
-	Interval        model.Duration   `yaml:"interval"`
-	InputSeries     []series         `yaml:"input_series"`
-	AlertRuleTests  []alertTestCase  `yaml:"alert_rule_test,omitempty"`
-	PromqlExprTests []promqlTestCase `yaml:"promql_expr_test,omitempty"`
-	ExternalLabels  labels.Labels    `yaml:"external_labels,omitempty"`
-	TestGroupName   string           `yaml:"name,omitempty"`
+	Interval        model.Duration     `yaml:"interval"`
+	InputSeries     []series           `yaml:"input_series"`
+	InputExemplars  []inputExemplar    `yaml:"input_exemplars,omitempty"`
+	AlertRuleTests  []alertTestCase    `yaml:"alert_rule_test,omitempty"`
+	PromqlExprTests []promqlTestCase   `yaml:"promql_expr_test,omitempty"`
+	ExemplarTests   []exemplarTestCase `yaml:"exemplar_test,omitempty"`
+	ExternalLabels  labels.Labels      `yaml:"external_labels,omitempty"`
+	TestGroupName   string             `yaml:"name,omitempty"`
 }
 
 // test performs the unit tests.
@@ -389,12 +393,106 @@ Outer:
 		}
 	}
 
+	// Checking exemplars.
+	if len(tg.ExemplarTests) > 0 {
+		es, err := tg.exemplarStorage()
+		if err != nil {
+			return append(errs, err)
+		}
+		eq, err := es.ExemplarQuerier(context.Background())
+		if err != nil {
+			return append(errs, err)
+		}
+	ExemplarOuter:
+		for _, testCase := range tg.ExemplarTests {
+			matchers, err := parser.ParseMetricSelector(testCase.Series)
+			if err != nil {
+				err = errors.Wrapf(err, "series %q", testCase.Series)
+				errs = append(errs, errors.Errorf("    series: %q, time: %s, err: %s", testCase.Series,
+					testCase.EvalTime.String(), err.Error()))
+				continue
+			}
+			res, err := eq.Select(0, int64(time.Duration(testCase.EvalTime)/time.Millisecond), matchers)
+			if err != nil {
+				errs = append(errs, errors.Errorf("    series: %q, time: %s, err: %s", testCase.Series,
+					testCase.EvalTime.String(), err.Error()))
+				continue
+			}
+
+			var gotExemplars []parsedExemplar
+			for _, r := range res {
+				for _, e := range r.Exemplars {
+					gotExemplars = append(gotExemplars, parsedExemplar{
+						Labels: e.Labels.Copy(),
+						Value:  e.Value,
+						Ts:     e.Ts,
+					})
+				}
+			}
+
+			var expExemplars []parsedExemplar
+			for _, e := range testCase.ExpExemplars {
+				lb, err := parser.ParseMetric(e.Labels)
+				if err != nil {
+					err = errors.Wrapf(err, "labels %q", e.Labels)
+					errs = append(errs, errors.Errorf("    series: %q, time: %s, err: %s", testCase.Series,
+						testCase.EvalTime.String(), err.Error()))
+					continue ExemplarOuter
+				}
+				expExemplars = append(expExemplars, parsedExemplar{
+					Labels: lb,
+					Value:  e.Value,
+					Ts:     int64(time.Duration(e.Timestamp) / time.Millisecond),
+				})
+			}
+
+			sort.Slice(expExemplars, func(i, j int) bool {
+				return expExemplars[i].less(expExemplars[j])
+			})
+			sort.Slice(gotExemplars, func(i, j int) bool {
+				return gotExemplars[i].less(gotExemplars[j])
+			})
+			if !reflect.DeepEqual(expExemplars, gotExemplars) {
+				errs = append(errs, errors.Errorf("    series: %q, time: %s,\n        exp:%#v\n        got:%#v", testCase.Series,
+					testCase.EvalTime.String(), parsedExemplarsString(expExemplars), parsedExemplarsString(gotExemplars)))
+			}
+		}
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
 	return nil
 }
 
+// exemplarStorage loads the input exemplars into a fresh exemplar storage.
+func (tg *testGroup) exemplarStorage() (tsdb.ExemplarStorage, error) {
+	es, err := tsdb.NewCircularExemplarStorage(len(tg.InputExemplars)+1, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, ie := range tg.InputExemplars {
+		lset, err := parser.ParseMetric(ie.Series)
+		if err != nil {
+			return nil, errors.Wrapf(err, "series %q", ie.Series)
+		}
+		elset, err := parser.ParseMetric(ie.Labels)
+		if err != nil {
+			return nil, errors.Wrapf(err, "exemplar labels %q", ie.Labels)
+		}
+		err = es.AddExemplar(lset, exemplar.Exemplar{
+			Labels: elset,
+			Value:  ie.Value,
+			Ts:     int64(time.Duration(ie.Timestamp) / time.Millisecond),
+			HasTs:  true,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "add exemplar for series %q", ie.Series)
+		}
+	}
+	return es, nil
+}
+
 // seriesLoadingString returns the input series in PromQL notation.
 func (tg *testGroup) seriesLoadingString() string {
 
@@ -523,6 +621,26 @@ type promqlTestCase struct {
 	ExpSamples []sample       `yaml:"exp_samples"`
 }
 
+// inputExemplar is an exemplar attached to one of the input series.
+type inputExemplar struct {
+	Series    string         `yaml:"series"`
+	Labels    string         `yaml:"labels"`
+	Value     float64        `yaml:"value"`
+	Timestamp model.Duration `yaml:"timestamp"`
+}
+
+type exemplarTestCase struct {
+	Series       string             `yaml:"series"`
+	EvalTime     model.Duration     `yaml:"eval_time"`
+	ExpExemplars []expectedExemplar `yaml:"exp_exemplars"`
+}
+
+type expectedExemplar struct {
+	Labels    string         `yaml:"labels"`
+	Value     float64        `yaml:"value"`
+	Timestamp model.Duration `yaml:"timestamp"`
+}
+
 type sample struct {
 	Labels string  `yaml:"labels"`
 	Value  float64 `yaml:"value"`
@@ -548,3 +666,34 @@ func parsedSamplesString(pss []parsedSample) string {
 func (ps *parsedSample) String() string {
 	return ps.Labels.String() + " " + strconv.FormatFloat(ps.Value, 'E', -1, 64)
 }
+
+// parsedExemplar is an exemplar with parsed Labels.
+type parsedExemplar struct {
+	Labels labels.Labels
+	Value  float64
+	Ts     int64
+}
+
+func (pe parsedExemplar) less(other parsedExemplar) bool {
+	diff := labels.Compare(pe.Labels, other.Labels)
+	if diff != 0 {
+		return diff < 0
+	}
+	return pe.Ts < other.Ts
+}
+
+func parsedExemplarsString(pes []parsedExemplar) string {
+	if len(pes) == 0 {
+		return "nil"
+	}
+	s := pes[0].String()
+	for _, pe := range pes[1:] {
+		s += ", " + pe.String()
+	}
+	return s
+}
+
+func (pe *parsedExemplar) String() string {
+	return pe.Labels.String() + " " + strconv.FormatFloat(pe.Value, 'E', -1, 64) +
+		" @" + model.Duration(time.Duration(pe.Ts)*time.Millisecond).String()
+}
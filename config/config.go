@@ -74,18 +74,11 @@ func Load(s string) (*Config, error) {
 	return cfg, nil
 }
 
-// LoadFile parses the given YAML file into a Config.
+// LoadFile parses the given YAML file into a Config. Partial files referenced
+// through the include directive are merged in; environment variables are left
+// untouched.
 func LoadFile(filename string) (*Config, error) {
-	content, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-	cfg, err := Load(string(content))
-	if err != nil {
-		return nil, errors.Wrapf(err, "parsing YAML file %s", filename)
-	}
-	cfg.SetDirectory(filepath.Dir(filename))
-	return cfg, nil
+	return LoadFileExpanded(filename, false)
 }
 
 // The defaults applied before parsing the respective config sections.
@@ -166,6 +159,7 @@ type Config struct {
 	GlobalConfig   GlobalConfig    `yaml:"global"`
 	AlertingConfig AlertingConfig  `yaml:"alerting,omitempty"`
 	RuleFiles      []string        `yaml:"rule_files,omitempty"`
+	Include        []string        `yaml:"include,omitempty"`
 	ScrapeConfigs  []*ScrapeConfig `yaml:"scrape_configs,omitempty"`
 
 	RemoteWriteConfigs []*RemoteWriteConfig `yaml:"remote_write,omitempty"`
@@ -179,6 +173,9 @@ func (c *Config) SetDirectory(dir string) {
 	for i, file := range c.RuleFiles {
 		c.RuleFiles[i] = config.JoinDir(dir, file)
 	}
+	for i, file := range c.Include {
+		c.Include[i] = config.JoinDir(dir, file)
+	}
 	for _, c := range c.ScrapeConfigs {
 		c.SetDirectory(dir)
 	}
@@ -350,6 +347,9 @@ type ScrapeConfig struct {
 	HonorLabels bool `yaml:"honor_labels,omitempty"`
 	// Indicator whether the scraped timestamps should be respected.
 	HonorTimestamps bool `yaml:"honor_timestamps"`
+	// Indicator whether malformed lines in the scraped data should be
+	// skipped instead of failing the scrape.
+	TolerantParsing bool `yaml:"tolerant_parsing,omitempty"`
 	// A set of query parameters with which the target is scraped.
 	Params url.Values `yaml:"params,omitempty"`
 	// How frequently to scrape the targets of this scrape config.
@@ -685,6 +685,11 @@ type QueueConfig struct {
 	MinBackoff       model.Duration `yaml:"min_backoff,omitempty"`
 	MaxBackoff       model.Duration `yaml:"max_backoff,omitempty"`
 	RetryOnRateLimit bool           `yaml:"retry_on_http_429,omitempty"`
+
+	// Suppress samples that are older than or identical to the last sample
+	// sent for the same series in the same shard, e.g. resent after a WAL
+	// replay overlap. For receivers that reject out-of-order writes.
+	EnforceInOrder bool `yaml:"enforce_in_order,omitempty"`
 }
 
 // MetadataConfig is the configuration for sending metadata to remote
@@ -0,0 +1,42 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// SectionsYAML renders each top-level section of the configuration as YAML,
+// keyed by section name. Secrets are masked by the YAML marshallers, so the
+// result is safe to expose and to compare for change detection.
+func (c *Config) SectionsYAML() (map[string]string, error) {
+	sections := map[string]interface{}{
+		"global":         c.GlobalConfig,
+		"rule_files":     c.RuleFiles,
+		"scrape_configs": c.ScrapeConfigs,
+		"alerting":       c.AlertingConfig,
+		"remote_write":   c.RemoteWriteConfigs,
+		"remote_read":    c.RemoteReadConfigs,
+	}
+	out := make(map[string]string, len(sections))
+	for name, v := range sections {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshaling section %q", name)
+		}
+		out[name] = string(b)
+	}
+	return out, nil
+}
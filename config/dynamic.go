@@ -0,0 +1,75 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Subdirectories of the dynamic configuration directory. Fragments placed
+// there, either by hand or through the dynamic configuration API, are merged
+// with the static configuration file on every reload.
+const (
+	// DynamicScrapeConfigsDir holds one scrape config document per file.
+	DynamicScrapeConfigsDir = "scrape_configs"
+	// DynamicRulesDir holds regular rule files.
+	DynamicRulesDir = "rules"
+)
+
+// LoadDynamic merges the dynamically managed configuration fragments below
+// dir into cfg. An empty dir or missing subdirectories are no-ops.
+func LoadDynamic(cfg *Config, dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, DynamicScrapeConfigsDir, "*.yml"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, fn := range files {
+		content, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return errors.Wrapf(err, "reading dynamic scrape config %s", fn)
+		}
+		var sc ScrapeConfig
+		if err := yaml.UnmarshalStrict(content, &sc); err != nil {
+			return errors.Wrapf(err, "parsing dynamic scrape config %s", fn)
+		}
+		cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, &sc)
+	}
+
+	ruleFiles, err := filepath.Glob(filepath.Join(dir, DynamicRulesDir, "*.yml"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(ruleFiles)
+	cfg.RuleFiles = append(cfg.RuleFiles, ruleFiles...)
+
+	// Job names must stay unique across the merged configuration.
+	jobNames := map[string]struct{}{}
+	for _, scfg := range cfg.ScrapeConfigs {
+		if _, ok := jobNames[scfg.JobName]; ok {
+			return errors.Errorf("found multiple scrape configs with job name %q", scfg.JobName)
+		}
+		jobNames[scfg.JobName] = struct{}{}
+	}
+	return nil
+}
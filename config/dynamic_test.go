@@ -0,0 +1,68 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDynamic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamic-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, DynamicScrapeConfigsDir), 0777))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, DynamicRulesDir), 0777))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, DynamicScrapeConfigsDir, "dynamic.yml"),
+		[]byte("job_name: dynamic\n"), 0666))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, DynamicRulesDir, "dynamic-rules.yml"),
+		[]byte("groups: []\n"), 0666))
+
+	cfg, err := Load("scrape_configs: [{job_name: static}]")
+	require.NoError(t, err)
+	require.NoError(t, LoadDynamic(cfg, dir))
+
+	require.Equal(t, 2, len(cfg.ScrapeConfigs))
+	require.Equal(t, "dynamic", cfg.ScrapeConfigs[1].JobName)
+	require.Equal(t, []string{filepath.Join(dir, DynamicRulesDir, "dynamic-rules.yml")}, cfg.RuleFiles)
+}
+
+func TestLoadDynamicDuplicateJobName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamic-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, DynamicScrapeConfigsDir), 0777))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, DynamicScrapeConfigsDir, "static.yml"),
+		[]byte("job_name: static\n"), 0666))
+
+	cfg, err := Load("scrape_configs: [{job_name: static}]")
+	require.NoError(t, err)
+	require.Error(t, LoadDynamic(cfg, dir))
+}
+
+func TestLoadDynamicEmptyDir(t *testing.T) {
+	cfg, err := Load("scrape_configs: [{job_name: static}]")
+	require.NoError(t, err)
+	require.NoError(t, LoadDynamic(cfg, ""))
+	require.Equal(t, 1, len(cfg.ScrapeConfigs))
+}
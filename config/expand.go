@@ -0,0 +1,117 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	common_config "github.com/prometheus/common/config"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var envVarRe = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// ExpandEnvVars replaces ${VAR} references in content with the value of the
+// corresponding environment variable. Unset variables expand to the empty
+// string.
+func ExpandEnvVars(content []byte) []byte {
+	return envVarRe.ReplaceAllFunc(content, func(m []byte) []byte {
+		name := envVarRe.FindSubmatch(m)[1]
+		return []byte(os.Getenv(string(name)))
+	})
+}
+
+// includeFragment is a partial configuration file merged in via the include
+// directive.
+type includeFragment struct {
+	ScrapeConfigs []*ScrapeConfig `yaml:"scrape_configs,omitempty"`
+	RuleFiles     []string        `yaml:"rule_files,omitempty"`
+}
+
+// LoadFileExpanded works like LoadFile but optionally expands ${ENV_VAR}
+// references in the file and merges partial files listed under the include
+// directive. The expanded configuration is what ends up being served via the
+// status config API.
+func LoadFileExpanded(filename string, expandEnv bool) (*Config, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if expandEnv {
+		content = ExpandEnvVars(content)
+	}
+	cfg, err := Load(string(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing YAML file %s", filename)
+	}
+	cfg.SetDirectory(filepath.Dir(filename))
+
+	if err := loadIncludes(cfg, expandEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadIncludes merges the scrape configs and rule files of all partial files
+// matched by the include globs into cfg.
+func loadIncludes(cfg *Config, expandEnv bool) error {
+	for _, pattern := range cfg.Include {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+		for _, fn := range files {
+			content, err := ioutil.ReadFile(fn)
+			if err != nil {
+				return errors.Wrapf(err, "reading included file %s", fn)
+			}
+			if expandEnv {
+				content = ExpandEnvVars(content)
+			}
+			var frag includeFragment
+			if err := yaml.UnmarshalStrict(content, &frag); err != nil {
+				return errors.Wrapf(err, "parsing included file %s", fn)
+			}
+
+			dir := filepath.Dir(fn)
+			for _, sc := range frag.ScrapeConfigs {
+				sc.SetDirectory(dir)
+				cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, sc)
+			}
+			for _, rf := range frag.RuleFiles {
+				cfg.RuleFiles = append(cfg.RuleFiles, common_config.JoinDir(dir, rf))
+			}
+		}
+	}
+
+	if len(cfg.Include) == 0 {
+		return nil
+	}
+
+	// Job names must stay unique across the merged configuration.
+	jobNames := map[string]struct{}{}
+	for _, scfg := range cfg.ScrapeConfigs {
+		if _, ok := jobNames[scfg.JobName]; ok {
+			return errors.Errorf("found multiple scrape configs with job name %q", scfg.JobName)
+		}
+		jobNames[scfg.JobName] = struct{}{}
+	}
+	return nil
+}
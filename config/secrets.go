@@ -0,0 +1,318 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	common_config "github.com/prometheus/common/config"
+)
+
+// DefaultSecretRefreshInterval is how long a fetched secret is cached before
+// the provider is asked again, which picks up rotated secrets.
+const DefaultSecretRefreshInterval = 1 * time.Minute
+
+// SecretProvider fetches the current value of a single secret from an
+// external source.
+type SecretProvider interface {
+	// Fetch returns the current value of the secret.
+	Fetch(ctx context.Context) (string, error)
+}
+
+// SecretProviderFunc instantiates a SecretProvider for the given reference,
+// which is the part of the secret reference after the scheme separator.
+type SecretProviderFunc func(ref string) (SecretProvider, error)
+
+var (
+	secretProvidersMtx sync.RWMutex
+	secretProviders    = map[string]SecretProviderFunc{
+		"file":  newFileSecretProvider,
+		"env":   newEnvSecretProvider,
+		"exec":  newExecSecretProvider,
+		"vault": newVaultSecretProvider,
+	}
+)
+
+// RegisterSecretProvider makes a secret provider available under the given
+// reference scheme, e.g. "vault" for references of the form "vault://...".
+func RegisterSecretProvider(scheme string, f SecretProviderFunc) {
+	secretProvidersMtx.Lock()
+	defer secretProvidersMtx.Unlock()
+	secretProviders[scheme] = f
+}
+
+func lookupSecretProvider(scheme string) (SecretProviderFunc, bool) {
+	secretProvidersMtx.RLock()
+	defer secretProvidersMtx.RUnlock()
+	f, ok := secretProviders[scheme]
+	return f, ok
+}
+
+// isSecretRef reports whether s looks like a reference to a registered
+// secret provider rather than an inline secret.
+func isSecretRef(s string) bool {
+	i := strings.Index(s, "://")
+	if i < 0 {
+		return false
+	}
+	_, ok := lookupSecretProvider(s[:i])
+	return ok
+}
+
+type cachedSecret struct {
+	provider SecretProvider
+	value    string
+	fetched  time.Time
+}
+
+var (
+	secretCacheMtx sync.Mutex
+	secretCache    = map[string]*cachedSecret{}
+)
+
+// ResolveSecret resolves a secret reference of the form "<scheme>://<ref>"
+// through the provider registered for the scheme. Values are cached for
+// DefaultSecretRefreshInterval so that rotated secrets are picked up on the
+// next resolution without hitting the provider on every call.
+func ResolveSecret(ctx context.Context, ref string) (string, error) {
+	i := strings.Index(ref, "://")
+	if i < 0 {
+		return "", errors.Errorf("invalid secret reference %q", ref)
+	}
+	newProvider, ok := lookupSecretProvider(ref[:i])
+	if !ok {
+		return "", errors.Errorf("unknown secret provider scheme %q", ref[:i])
+	}
+
+	secretCacheMtx.Lock()
+	defer secretCacheMtx.Unlock()
+
+	cs, ok := secretCache[ref]
+	if !ok {
+		p, err := newProvider(ref[i+len("://"):])
+		if err != nil {
+			return "", errors.Wrapf(err, "initializing secret provider for %q", ref)
+		}
+		cs = &cachedSecret{provider: p}
+		secretCache[ref] = cs
+	}
+	if time.Since(cs.fetched) < DefaultSecretRefreshInterval {
+		return cs.value, nil
+	}
+	v, err := cs.provider.Fetch(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching secret %q", ref)
+	}
+	cs.value = v
+	cs.fetched = time.Now()
+	return v, nil
+}
+
+// ResolveSecrets replaces secret references in the bearer tokens and
+// passwords of all scrape, alerting, remote-write and remote-read configs
+// with the values fetched from their providers. Inline secrets are left
+// untouched.
+func (c *Config) ResolveSecrets(ctx context.Context) error {
+	for _, scfg := range c.ScrapeConfigs {
+		if err := resolveHTTPClientSecrets(ctx, &scfg.HTTPClientConfig); err != nil {
+			return errors.Wrapf(err, "scrape config %q", scfg.JobName)
+		}
+	}
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if err := resolveHTTPClientSecrets(ctx, &amcfg.HTTPClientConfig); err != nil {
+			return errors.Wrap(err, "alertmanager config")
+		}
+	}
+	for _, rwcfg := range c.RemoteWriteConfigs {
+		if err := resolveHTTPClientSecrets(ctx, &rwcfg.HTTPClientConfig); err != nil {
+			return errors.Wrapf(err, "remote write config %q", rwcfg.Name)
+		}
+	}
+	for _, rrcfg := range c.RemoteReadConfigs {
+		if err := resolveHTTPClientSecrets(ctx, &rrcfg.HTTPClientConfig); err != nil {
+			return errors.Wrapf(err, "remote read config %q", rrcfg.Name)
+		}
+	}
+	return nil
+}
+
+func resolveHTTPClientSecrets(ctx context.Context, cfg *common_config.HTTPClientConfig) error {
+	if err := resolveSecretField(ctx, &cfg.BearerToken); err != nil {
+		return err
+	}
+	if cfg.BasicAuth != nil {
+		if err := resolveSecretField(ctx, &cfg.BasicAuth.Password); err != nil {
+			return err
+		}
+	}
+	if cfg.Authorization != nil {
+		if err := resolveSecretField(ctx, &cfg.Authorization.Credentials); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveSecretField(ctx context.Context, s *common_config.Secret) error {
+	if !isSecretRef(string(*s)) {
+		return nil
+	}
+	v, err := ResolveSecret(ctx, string(*s))
+	if err != nil {
+		return err
+	}
+	*s = common_config.Secret(v)
+	return nil
+}
+
+type fileSecretProvider struct {
+	path string
+}
+
+func newFileSecretProvider(ref string) (SecretProvider, error) {
+	if ref == "" {
+		return nil, errors.New("file secret reference is empty")
+	}
+	return &fileSecretProvider{path: ref}, nil
+}
+
+func (p *fileSecretProvider) Fetch(_ context.Context) (string, error) {
+	b, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\n"), nil
+}
+
+type envSecretProvider struct {
+	name string
+}
+
+func newEnvSecretProvider(ref string) (SecretProvider, error) {
+	if ref == "" {
+		return nil, errors.New("env secret reference is empty")
+	}
+	return &envSecretProvider{name: ref}, nil
+}
+
+func (p *envSecretProvider) Fetch(_ context.Context) (string, error) {
+	v, ok := os.LookupEnv(p.name)
+	if !ok {
+		return "", errors.Errorf("environment variable %q is not set", p.name)
+	}
+	return v, nil
+}
+
+type execSecretProvider struct {
+	args []string
+}
+
+func newExecSecretProvider(ref string) (SecretProvider, error) {
+	args := strings.Fields(ref)
+	if len(args) == 0 {
+		return nil, errors.New("exec secret reference is empty")
+	}
+	return &execSecretProvider{args: args}, nil
+}
+
+func (p *execSecretProvider) Fetch(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, p.args[0], p.args[1:]...).Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "running %q", strings.Join(p.args, " "))
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// vaultSecretProvider reads a single field of a Vault KV secret over the
+// HTTP API. References have the form "vault://host:port/secret/data/path#field"
+// and the token is taken from the VAULT_TOKEN environment variable.
+type vaultSecretProvider struct {
+	url   string
+	field string
+}
+
+func newVaultSecretProvider(ref string) (SecretProvider, error) {
+	u, err := url.Parse("vault://" + ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid vault secret reference %q", ref)
+	}
+	if u.Host == "" || u.Path == "" || u.Fragment == "" {
+		return nil, errors.Errorf("vault secret reference %q must have the form host:port/path#field", ref)
+	}
+	return &vaultSecretProvider{
+		url:   fmt.Sprintf("https://%s/v1%s", u.Host, u.Path),
+		field: u.Fragment,
+	}, nil
+}
+
+func (p *vaultSecretProvider) Fetch(ctx context.Context) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, p.url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("vault returned status %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return "", errors.Wrap(err, "decoding vault response")
+	}
+	fields := body.Data.Data
+	if fields == nil {
+		// KV version 1 secrets have the fields at the top data level.
+		var v1 struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(b, &v1); err != nil {
+			return "", errors.Wrap(err, "decoding vault response")
+		}
+		fields = v1.Data
+	}
+	v, ok := fields[p.field]
+	if !ok {
+		return "", errors.Errorf("field %q not found in vault secret", p.field)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.Errorf("field %q in vault secret is not a string", p.field)
+	}
+	return s, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labels
+
+import (
+	"encoding/binary"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Interned is a compact, immutable representation of a label set. All names
+// and values are stored length-prefixed in a single backing string, so a
+// whole label set is one allocation regardless of how many labels it has and
+// the garbage collector only sees a single pointer per series.
+type Interned struct {
+	data string
+}
+
+// Intern converts a label set to its interned representation. The labels
+// must already be sorted by name.
+func Intern(ls Labels) Interned {
+	var b InternBuilder
+	for _, l := range ls {
+		b.Add(l.Name, l.Value)
+	}
+	return b.Labels()
+}
+
+// IsZero reports whether the interned label set is empty.
+func (in Interned) IsZero() bool {
+	return in.data == ""
+}
+
+// Len returns the number of labels.
+func (in Interned) Len() int {
+	n := 0
+	for i := 0; i < len(in.data); {
+		_, i = decodeString(in.data, i)
+		_, i = decodeString(in.data, i)
+		n++
+	}
+	return n
+}
+
+// Range calls f for each label in order.
+func (in Interned) Range(f func(l Label)) {
+	for i := 0; i < len(in.data); {
+		var name, value string
+		name, i = decodeString(in.data, i)
+		value, i = decodeString(in.data, i)
+		f(Label{Name: name, Value: value})
+	}
+}
+
+// Get returns the value for the label with the given name.
+// Returns an empty string if the label doesn't exist.
+func (in Interned) Get(name string) string {
+	for i := 0; i < len(in.data); {
+		var n, v string
+		n, i = decodeString(in.data, i)
+		v, i = decodeString(in.data, i)
+		if n == name {
+			return v
+		}
+	}
+	return ""
+}
+
+// Labels decodes the interned representation back into a label set. The
+// returned strings share the backing string, only the slice is allocated.
+func (in Interned) Labels() Labels {
+	ls := make(Labels, 0, in.Len())
+	for i := 0; i < len(in.data); {
+		var name, value string
+		name, i = decodeString(in.data, i)
+		value, i = decodeString(in.data, i)
+		ls = append(ls, Label{Name: name, Value: value})
+	}
+	return ls
+}
+
+// Hash returns a hash value for the label set, identical to Labels.Hash of
+// the decoded set.
+func (in Interned) Hash() uint64 {
+	b := make([]byte, 0, 1024)
+	for i := 0; i < len(in.data); {
+		var name, value string
+		name, i = decodeString(in.data, i)
+		value, i = decodeString(in.data, i)
+		b = append(b, name...)
+		b = append(b, seps[0])
+		b = append(b, value...)
+		b = append(b, seps[0])
+	}
+	return xxhash.Sum64(b)
+}
+
+// String implements fmt.Stringer.
+func (in Interned) String() string {
+	return in.Labels().String()
+}
+
+// InternBuilder incrementally encodes a label set into the interned
+// representation. The scratch buffer is reused across Reset calls, so a
+// long-lived builder adds labels without allocating.
+type InternBuilder struct {
+	buf []byte
+}
+
+// Reset clears the builder for reuse.
+func (b *InternBuilder) Reset() {
+	b.buf = b.buf[:0]
+}
+
+// Add appends a label. Labels must be added sorted by name.
+func (b *InternBuilder) Add(name, value string) {
+	b.buf = encodeString(b.buf, name)
+	b.buf = encodeString(b.buf, value)
+}
+
+// Labels returns the interned label set built so far. The backing string is
+// the only allocation made.
+func (b *InternBuilder) Labels() Interned {
+	return Interned{data: string(b.buf)}
+}
+
+func encodeString(buf []byte, s string) []byte {
+	var sizeBuf [binary.MaxVarintLen32]byte
+	n := binary.PutUvarint(sizeBuf[:], uint64(len(s)))
+	buf = append(buf, sizeBuf[:n]...)
+	return append(buf, s...)
+}
+
+func decodeString(data string, i int) (string, int) {
+	// Inlined uvarint decoding over the string to avoid a byte slice copy.
+	size := 0
+	for shift := uint(0); ; shift += 7 {
+		b := data[i]
+		i++
+		size |= int(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+	}
+	return data[i : i+size], i + size
+}
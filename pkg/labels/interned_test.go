@@ -0,0 +1,59 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternedRoundTrip(t *testing.T) {
+	for _, ls := range []Labels{
+		nil,
+		{},
+		FromStrings("__name__", "up", "instance", "localhost:9090", "job", "prometheus"),
+		FromStrings("empty", "", "foo", "bar"),
+	} {
+		in := Intern(ls)
+		require.Equal(t, len(ls), in.Len())
+		if len(ls) == 0 {
+			require.True(t, in.IsZero())
+			continue
+		}
+		require.Equal(t, ls, in.Labels())
+		require.Equal(t, ls.Hash(), in.Hash())
+	}
+}
+
+func TestInternedGet(t *testing.T) {
+	in := Intern(FromStrings("__name__", "up", "job", "prometheus"))
+	require.Equal(t, "up", in.Get("__name__"))
+	require.Equal(t, "prometheus", in.Get("job"))
+	require.Equal(t, "", in.Get("missing"))
+}
+
+func TestInternBuilderReuse(t *testing.T) {
+	var b InternBuilder
+	b.Add("a", "1")
+	b.Add("b", "2")
+	first := b.Labels()
+
+	b.Reset()
+	b.Add("c", "3")
+	second := b.Labels()
+
+	require.Equal(t, FromStrings("a", "1", "b", "2"), first.Labels())
+	require.Equal(t, FromStrings("c", "3"), second.Labels())
+}
@@ -54,6 +54,14 @@ const (
 	LabelDrop Action = "labeldrop"
 	// LabelKeep drops any label not matching the regex.
 	LabelKeep Action = "labelkeep"
+	// KeepEqual drops targets for which the input does not match the target.
+	KeepEqual Action = "keepequal"
+	// DropEqual drops targets for which the input does match the target.
+	DropEqual Action = "dropequal"
+	// Lowercase maps input letters to their lower case.
+	Lowercase Action = "lowercase"
+	// Uppercase maps input letters to their upper case.
+	Uppercase Action = "uppercase"
 )
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface.
@@ -63,7 +71,7 @@ func (a *Action) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 	switch act := Action(strings.ToLower(s)); act {
-	case Replace, Keep, Drop, HashMod, LabelMap, LabelDrop, LabelKeep:
+	case Replace, Keep, Drop, HashMod, LabelMap, LabelDrop, LabelKeep, KeepEqual, DropEqual, Lowercase, Uppercase:
 		*a = act
 		return nil
 	}
@@ -103,7 +111,7 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if c.Modulus == 0 && c.Action == HashMod {
 		return errors.Errorf("relabel configuration for hashmod requires non-zero modulus")
 	}
-	if (c.Action == Replace || c.Action == HashMod) && c.TargetLabel == "" {
+	if (c.Action == Replace || c.Action == HashMod || c.Action == KeepEqual || c.Action == DropEqual || c.Action == Lowercase || c.Action == Uppercase) && c.TargetLabel == "" {
 		return errors.Errorf("relabel configuration for %s action requires 'target_label' value", c.Action)
 	}
 	if c.Action == Replace && !relabelTarget.MatchString(c.TargetLabel) {
@@ -112,10 +120,24 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if c.Action == LabelMap && !relabelTarget.MatchString(c.Replacement) {
 		return errors.Errorf("%q is invalid 'replacement' for %s action", c.Replacement, c.Action)
 	}
-	if c.Action == HashMod && !model.LabelName(c.TargetLabel).IsValid() {
+	if (c.Action == HashMod || c.Action == Lowercase || c.Action == Uppercase) && !model.LabelName(c.TargetLabel).IsValid() {
 		return errors.Errorf("%q is invalid 'target_label' for %s action", c.TargetLabel, c.Action)
 	}
 
+	if c.Action == Lowercase || c.Action == Uppercase {
+		if c.Replacement != DefaultRelabelConfig.Replacement {
+			return errors.Errorf("'replacement' can not be set for %s action", c.Action)
+		}
+	}
+	if c.Action == KeepEqual || c.Action == DropEqual {
+		if c.Regex.String() != DefaultRelabelConfig.Regex.String() ||
+			c.Modulus != DefaultRelabelConfig.Modulus ||
+			c.Separator != DefaultRelabelConfig.Separator ||
+			c.Replacement != DefaultRelabelConfig.Replacement {
+			return errors.Errorf("%s action requires only 'source_labels' and 'target_label', and no other fields", c.Action)
+		}
+	}
+
 	if c.Action == LabelDrop || c.Action == LabelKeep {
 		if c.SourceLabels != nil ||
 			c.TargetLabel != DefaultRelabelConfig.TargetLabel ||
@@ -208,6 +230,14 @@ func relabel(lset labels.Labels, cfg *Config) labels.Labels {
 		if !cfg.Regex.MatchString(val) {
 			return nil
 		}
+	case DropEqual:
+		if lset.Get(cfg.TargetLabel) == val {
+			return nil
+		}
+	case KeepEqual:
+		if lset.Get(cfg.TargetLabel) != val {
+			return nil
+		}
 	case Replace:
 		indexes := cfg.Regex.FindStringSubmatchIndex(val)
 		// If there is no match no replacement must take place.
@@ -225,6 +255,10 @@ func relabel(lset labels.Labels, cfg *Config) labels.Labels {
 			break
 		}
 		lb.Set(string(target), string(res))
+	case Lowercase:
+		lb.Set(cfg.TargetLabel, strings.ToLower(val))
+	case Uppercase:
+		lb.Set(cfg.TargetLabel, strings.ToUpper(val))
 	case HashMod:
 		mod := sum64(md5.Sum([]byte(val))) % cfg.Modulus
 		lb.Set(cfg.TargetLabel, fmt.Sprintf("%d", mod))
@@ -428,6 +428,104 @@ func TestRelabel(t *testing.T) {
 				"a": "foo",
 			}),
 		},
+		{ // Lowercase.
+			input: labels.FromMap(map[string]string{
+				"a": "FOO",
+				"b": "bar",
+			}),
+			relabel: []*Config{
+				{
+					SourceLabels: model.LabelNames{"a"},
+					TargetLabel:  "c",
+					Action:       Lowercase,
+				},
+			},
+			output: labels.FromMap(map[string]string{
+				"a": "FOO",
+				"b": "bar",
+				"c": "foo",
+			}),
+		},
+		{ // Uppercase.
+			input: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "bar",
+			}),
+			relabel: []*Config{
+				{
+					SourceLabels: model.LabelNames{"a"},
+					TargetLabel:  "c",
+					Action:       Uppercase,
+				},
+			},
+			output: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "bar",
+				"c": "FOO",
+			}),
+		},
+		{ // Keepequal keeps targets whose input matches the target label.
+			input: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "foo",
+			}),
+			relabel: []*Config{
+				{
+					SourceLabels: model.LabelNames{"a"},
+					TargetLabel:  "b",
+					Action:       KeepEqual,
+				},
+			},
+			output: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "foo",
+			}),
+		},
+		{ // Keepequal drops targets whose input does not match the target label.
+			input: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "bar",
+			}),
+			relabel: []*Config{
+				{
+					SourceLabels: model.LabelNames{"a"},
+					TargetLabel:  "b",
+					Action:       KeepEqual,
+				},
+			},
+			output: nil,
+		},
+		{ // Dropequal drops targets whose input matches the target label.
+			input: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "foo",
+			}),
+			relabel: []*Config{
+				{
+					SourceLabels: model.LabelNames{"a"},
+					TargetLabel:  "b",
+					Action:       DropEqual,
+				},
+			},
+			output: nil,
+		},
+		{ // Dropequal keeps targets whose input does not match the target label.
+			input: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "bar",
+			}),
+			relabel: []*Config{
+				{
+					SourceLabels: model.LabelNames{"a"},
+					TargetLabel:  "b",
+					Action:       DropEqual,
+				},
+			},
+			output: labels.FromMap(map[string]string{
+				"a": "foo",
+				"b": "bar",
+			}),
+		},
 	}
 
 	for _, test := range tests {
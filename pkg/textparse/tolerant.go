@@ -0,0 +1,128 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/adolli/prometheus/pkg/exemplar"
+	"github.com/adolli/prometheus/pkg/labels"
+)
+
+// ParseError describes a malformed line that a tolerant parser skipped.
+type ParseError struct {
+	// Line is the 1-based line number in the scraped input.
+	Line int
+	Err  error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// TolerantParser wraps a Parser and skips malformed lines instead of
+// aborting. The errors for skipped lines are collected and can be retrieved
+// via Errors after parsing finished.
+type TolerantParser struct {
+	b           []byte
+	contentType string
+
+	p        Parser
+	consumed int // Offset in b at which the inner parser was started.
+	errs     []ParseError
+}
+
+// NewTolerant returns a parser for the byte slice that skips lines failing to
+// parse rather than returning their error from Next.
+func NewTolerant(b []byte, contentType string) *TolerantParser {
+	return &TolerantParser{
+		b:           b,
+		contentType: contentType,
+		p:           New(b, contentType),
+	}
+}
+
+// Next advances the parser to the next sample. Malformed lines are recorded
+// and skipped; it only returns an error when the end of the input is reached.
+func (t *TolerantParser) Next() (Entry, error) {
+	for {
+		e, err := t.p.Next()
+		if err == nil || err == io.EOF {
+			return e, err
+		}
+
+		// Find the line the parser choked on and restart it after that
+		// line. The last consumed byte may already be the newline
+		// terminating the bad line, so step back by one before locating
+		// the line boundaries.
+		errPos := t.consumed + t.position()
+		if errPos > t.consumed {
+			errPos--
+		}
+		if errPos > len(t.b)-1 {
+			errPos = len(t.b) - 1
+		}
+		t.errs = append(t.errs, ParseError{
+			Line: bytes.Count(t.b[:errPos], []byte{'\n'}) + 1,
+			Err:  err,
+		})
+
+		next := bytes.IndexByte(t.b[errPos:], '\n')
+		if next < 0 {
+			return EntryInvalid, io.EOF
+		}
+		t.consumed = errPos + next + 1
+		t.p = New(t.b[t.consumed:], t.contentType)
+	}
+}
+
+// Errors returns the errors of all lines skipped so far.
+func (t *TolerantParser) Errors() []ParseError {
+	return t.errs
+}
+
+// position returns the current byte offset of the inner parser.
+func (t *TolerantParser) position() int {
+	switch p := t.p.(type) {
+	case *PromParser:
+		return p.l.i
+	case *OpenMetricsParser:
+		return p.l.i
+	}
+	return 0
+}
+
+// Series returns the bytes of the series, the timestamp if set, and the value
+// of the current sample.
+func (t *TolerantParser) Series() ([]byte, *int64, float64) { return t.p.Series() }
+
+// Help returns the metric name and help text in the current entry.
+func (t *TolerantParser) Help() ([]byte, []byte) { return t.p.Help() }
+
+// Type returns the metric name and type in the current entry.
+func (t *TolerantParser) Type() ([]byte, MetricType) { return t.p.Type() }
+
+// Unit returns the metric name and unit in the current entry.
+func (t *TolerantParser) Unit() ([]byte, []byte) { return t.p.Unit() }
+
+// Comment returns the text of the current comment.
+func (t *TolerantParser) Comment() []byte { return t.p.Comment() }
+
+// Metric writes the labels of the current sample into the passed labels.
+func (t *TolerantParser) Metric(l *labels.Labels) string { return t.p.Metric(l) }
+
+// Exemplar writes the exemplar of the current sample into the passed exemplar.
+func (t *TolerantParser) Exemplar(e *exemplar.Exemplar) bool { return t.p.Exemplar(e) }
@@ -0,0 +1,78 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparse
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adolli/prometheus/pkg/labels"
+)
+
+func TestTolerantParserSkipsInvalidLines(t *testing.T) {
+	input := `go_goroutines 33 123123
+this line is garbage
+go_gc_duration_seconds{quantile="0.5"} 0.02
+another{ bad line
+up 1
+`
+
+	p := NewTolerant([]byte(input), "")
+
+	var series []string
+	for {
+		et, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if et != EntrySeries {
+			continue
+		}
+		var lset labels.Labels
+		p.Metric(&lset)
+		series = append(series, lset.String())
+	}
+
+	require.Equal(t, []string{
+		`{__name__="go_goroutines"}`,
+		`{__name__="go_gc_duration_seconds", quantile="0.5"}`,
+		`{__name__="up"}`,
+	}, series)
+
+	errs := p.Errors()
+	require.Equal(t, 2, len(errs))
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, 4, errs[1].Line)
+}
+
+func TestTolerantParserCleanInput(t *testing.T) {
+	input := `up 1
+go_goroutines 33
+`
+	p := NewTolerant([]byte(input), "")
+	n := 0
+	for {
+		_, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		n++
+	}
+	require.Equal(t, 2, n)
+	require.Equal(t, 0, len(p.Errors()))
+}
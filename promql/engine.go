@@ -339,6 +339,15 @@ func (ng *Engine) SetQueryLogger(l QueryLogger) {
 	}
 }
 
+// ActiveQueries returns a snapshot of the queries the engine is currently
+// executing, or nil if no active query tracker is configured.
+func (ng *Engine) ActiveQueries() []ActiveQuery {
+	if ng.activeQueryTracker == nil {
+		return nil
+	}
+	return ng.activeQueryTracker.ActiveQueries()
+}
+
 // NewInstantQuery returns an evaluation query for the given expression at the given time.
 func (ng *Engine) NewInstantQuery(q storage.Queryable, qs string, ts time.Time) (Query, error) {
 	expr, err := parser.ParseExpr(qs)
@@ -489,6 +498,9 @@ func (ng *Engine) exec(ctx context.Context, q *query) (v parser.Value, ws storag
 					f = append(f, "spanID", spanCtx.SpanID())
 				}
 			}
+			if id := QueryIDFromContext(ctx); id != "" {
+				f = append(f, "queryID", id)
+			}
 			if origin := ctx.Value(QueryOrigin{}); origin != nil {
 				for k, v := range origin.(map[string]interface{}) {
 					f = append(f, k, v)
@@ -515,6 +527,7 @@ func (ng *Engine) exec(ctx context.Context, q *query) (v parser.Value, ws storag
 			return nil, nil, contextErr(err, "query queue")
 		}
 		defer ng.activeQueryTracker.Delete(queryIndex)
+		ctx = contextWithActiveQueryEntry(ctx, ng.activeQueryTracker.entry(queryIndex))
 	}
 	queueSpanTimer.Finish()
 
@@ -579,6 +592,7 @@ func (ng *Engine) execEvalStmt(ctx context.Context, query *query, s *parser.Eval
 			logger:                   ng.logger,
 			lookbackDelta:            ng.lookbackDelta,
 			noStepSubqueryIntervalFn: ng.noStepSubqueryIntervalFn,
+			activeQuery:              activeQueryEntryFromContext(ctx),
 		}
 
 		val, warnings, err := evaluator.Eval(s.Expr)
@@ -629,6 +643,7 @@ func (ng *Engine) execEvalStmt(ctx context.Context, query *query, s *parser.Eval
 		logger:                   ng.logger,
 		lookbackDelta:            ng.lookbackDelta,
 		noStepSubqueryIntervalFn: ng.noStepSubqueryIntervalFn,
+		activeQuery:              activeQueryEntryFromContext(ctx),
 	}
 	val, warnings, err := evaluator.Eval(s.Expr)
 	if err != nil {
@@ -860,6 +875,15 @@ type evaluator struct {
 	logger                   log.Logger
 	lookbackDelta            time.Duration
 	noStepSubqueryIntervalFn func(rangeMillis int64) int64
+	activeQuery              *activeQueryEntry
+}
+
+// observeActiveQuerySamples publishes the evaluator's current sample count to
+// the active query tracker, if the query is tracked.
+func (ev *evaluator) observeActiveQuerySamples() {
+	if ev.activeQuery != nil {
+		ev.activeQuery.observeSamples(int64(ev.currentSamples))
+	}
 }
 
 // errorf causes a panic with the input formatted into an error.
@@ -1045,6 +1069,7 @@ func (ev *evaluator) rangeEval(funcCall func([]parser.Value, *EvalNodeHelper) (V
 		if ev.currentSamples > ev.maxSamples {
 			ev.error(ErrTooManySamples(env))
 		}
+		ev.observeActiveQuerySamples()
 
 		// If this could be an instant query, shortcut so as not to change sort order.
 		if ev.endTimestamp == ev.startTimestamp {
@@ -1296,6 +1321,7 @@ func (ev *evaluator) eval(expr parser.Expr) (parser.Value, storage.Warnings) {
 			} else {
 				putPointSlice(ss.Points)
 			}
+			ev.observeActiveQuerySamples()
 		}
 
 		ev.currentSamples -= len(points)
@@ -1461,6 +1487,7 @@ func (ev *evaluator) eval(expr parser.Expr) (parser.Value, storage.Warnings) {
 			logger:                   ev.logger,
 			lookbackDelta:            ev.lookbackDelta,
 			noStepSubqueryIntervalFn: ev.noStepSubqueryIntervalFn,
+			activeQuery:              ev.activeQuery,
 		}
 
 		if e.Step != 0 {
@@ -1502,6 +1529,7 @@ func (ev *evaluator) eval(expr parser.Expr) (parser.Value, storage.Warnings) {
 			logger:                   ev.logger,
 			lookbackDelta:            ev.lookbackDelta,
 			noStepSubqueryIntervalFn: ev.noStepSubqueryIntervalFn,
+			activeQuery:              ev.activeQuery,
 		}
 		res, ws := newEv.eval(e.Expr)
 		ev.currentSamples = newEv.currentSamples
@@ -2065,6 +2093,47 @@ type groupedAggregation struct {
 	groupCount  int
 	heap        vectorByValueHeap
 	reverseHeap vectorByReverseValueHeap
+	limitHeap   limitKHeap
+}
+
+// hashedSample pairs a sample with the hash of its label set so that limitk
+// can keep a deterministic subset of series.
+type hashedSample struct {
+	hash uint64
+	Sample
+}
+
+// limitKHeap is a max-heap of samples ordered by label hash. It keeps the k
+// samples with the smallest hashes, popping the largest hash when full.
+type limitKHeap []hashedSample
+
+func (s limitKHeap) Len() int { return len(s) }
+
+func (s limitKHeap) Less(i, j int) bool { return s[i].hash > s[j].hash }
+
+func (s limitKHeap) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *limitKHeap) Push(x interface{}) {
+	*s = append(*s, x.(hashedSample))
+}
+
+func (s *limitKHeap) Pop() interface{} {
+	old := *s
+	n := len(old)
+	el := old[n-1]
+	*s = old[0 : n-1]
+	return el
+}
+
+// ratioSampled deterministically decides whether a series is kept by
+// limit_ratio. A ratio r in (0, 1] selects approximately r of all series;
+// the negative ratio -(1-r) selects exactly the complementary set.
+func ratioSampled(metric labels.Labels, r float64) bool {
+	q := float64(metric.Hash()) / float64(math.MaxUint64)
+	if r >= 0 {
+		return q < r
+	}
+	return q >= 1+r
 }
 
 // aggregation evaluates an aggregation operation on a Vector.
@@ -2072,7 +2141,7 @@ func (ev *evaluator) aggregation(op parser.ItemType, grouping []string, without
 
 	result := map[uint64]*groupedAggregation{}
 	var k int64
-	if op == parser.TOPK || op == parser.BOTTOMK {
+	if op == parser.TOPK || op == parser.BOTTOMK || op == parser.LIMITK {
 		f := param.(float64)
 		if !convertibleToInt64(f) {
 			ev.errorf("Scalar value %v overflows int64", f)
@@ -2082,6 +2151,16 @@ func (ev *evaluator) aggregation(op parser.ItemType, grouping []string, without
 			return Vector{}
 		}
 	}
+	var r float64
+	if op == parser.LIMIT_RATIO {
+		r = param.(float64)
+		if math.IsNaN(r) || r < -1 || r > 1 {
+			ev.errorf("Ratio value %v out of range [-1, 1]", r)
+		}
+		if r == 0 {
+			return Vector{}
+		}
+	}
 	var q float64
 	if op == parser.QUANTILE {
 		q = param.(float64)
@@ -2103,6 +2182,11 @@ func (ev *evaluator) aggregation(op parser.ItemType, grouping []string, without
 	for _, s := range vec {
 		metric := s.Metric
 
+		// Unselected series do not contribute empty groups to the result.
+		if op == parser.LIMIT_RATIO && !ratioSampled(metric, r) {
+			continue
+		}
+
 		if op == parser.COUNT_VALUES {
 			lb.Reset(metric)
 			lb.Set(valueLabel, strconv.FormatFloat(s.V, 'f', -1, 64))
@@ -2166,6 +2250,14 @@ func (ev *evaluator) aggregation(op parser.ItemType, grouping []string, without
 					Point:  Point{V: s.V},
 					Metric: s.Metric,
 				})
+			case parser.LIMITK:
+				result[groupingKey].limitHeap = make(limitKHeap, 0, resultSize)
+				heap.Push(&result[groupingKey].limitHeap, hashedSample{
+					hash:   s.Metric.Hash(),
+					Sample: Sample{Point: Point{V: s.V}, Metric: s.Metric},
+				})
+			case parser.LIMIT_RATIO:
+				result[groupingKey].heap = vectorByValueHeap{s}
 			case parser.GROUP:
 				result[groupingKey].value = 1
 			}
@@ -2242,6 +2334,21 @@ func (ev *evaluator) aggregation(op parser.ItemType, grouping []string, without
 				})
 			}
 
+		case parser.LIMITK:
+			h := s.Metric.Hash()
+			if int64(len(group.limitHeap)) < k || group.limitHeap[0].hash > h {
+				if int64(len(group.limitHeap)) == k {
+					heap.Pop(&group.limitHeap)
+				}
+				heap.Push(&group.limitHeap, hashedSample{
+					hash:   h,
+					Sample: Sample{Point: Point{V: s.V}, Metric: s.Metric},
+				})
+			}
+
+		case parser.LIMIT_RATIO:
+			group.heap = append(group.heap, s)
+
 		case parser.QUANTILE:
 			group.heap = append(group.heap, s)
 
@@ -2287,6 +2394,24 @@ func (ev *evaluator) aggregation(op parser.ItemType, grouping []string, without
 			}
 			continue // Bypass default append.
 
+		case parser.LIMITK:
+			for _, v := range aggr.limitHeap {
+				enh.Out = append(enh.Out, Sample{
+					Metric: v.Metric,
+					Point:  Point{V: v.V},
+				})
+			}
+			continue // Bypass default append.
+
+		case parser.LIMIT_RATIO:
+			for _, v := range aggr.heap {
+				enh.Out = append(enh.Out, Sample{
+					Metric: v.Metric,
+					Point:  Point{V: v.V},
+				})
+			}
+			continue // Bypass default append.
+
 		case parser.QUANTILE:
 			aggr.value = quantile(q, aggr.heap)
 
@@ -2326,6 +2451,42 @@ func NewOriginContext(ctx context.Context, data map[string]interface{}) context.
 	return context.WithValue(ctx, QueryOrigin{}, data)
 }
 
+// queryID is the context key under which the correlation ID assigned to a
+// query is stored.
+type queryID struct{}
+
+// ContextWithQueryID returns a new context with the given query ID attached.
+func ContextWithQueryID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, queryID{}, id)
+}
+
+// QueryIDFromContext returns the query ID attached to the context, or an
+// empty string if none was assigned.
+func QueryIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(queryID{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// activeQueryKey is the context key under which the live counters of a
+// tracked query are stored.
+type activeQueryKey struct{}
+
+func contextWithActiveQueryEntry(ctx context.Context, e *activeQueryEntry) context.Context {
+	if e == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, activeQueryKey{}, e)
+}
+
+func activeQueryEntryFromContext(ctx context.Context) *activeQueryEntry {
+	if e, ok := ctx.Value(activeQueryKey{}).(*activeQueryEntry); ok {
+		return e
+	}
+	return nil
+}
+
 func formatDate(t time.Time) string {
 	return t.UTC().Format("2006-01-02T15:04:05.000Z07:00")
 }
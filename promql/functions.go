@@ -278,6 +278,62 @@ func funcSortDesc(vals []parser.Value, args parser.Expressions, enh *EvalNodeHel
 	return Vector(byValueSorter)
 }
 
+// compareLabelValues compares two label values, numerically if both parse as
+// floats and lexicographically otherwise.
+func compareLabelValues(a, b string) int {
+	if fa, errA := strconv.ParseFloat(a, 64); errA == nil {
+		if fb, errB := strconv.ParseFloat(b, 64); errB == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return +1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// === sort_by_label(vector parser.ValueTypeVector, label parser.ValueTypeString...) Vector ===
+func funcSortByLabel(vals []parser.Value, args parser.Expressions, enh *EvalNodeHelper) Vector {
+	lbls := make([]string, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		lbls = append(lbls, stringFromArg(arg))
+	}
+	vec := vals[0].(Vector)
+	sort.SliceStable(vec, func(i, j int) bool {
+		for _, label := range lbls {
+			if c := compareLabelValues(vec[i].Metric.Get(label), vec[j].Metric.Get(label)); c != 0 {
+				return c < 0
+			}
+		}
+		// Fall back to the full label sets so the order does not depend on
+		// storage iteration order.
+		return labels.Compare(vec[i].Metric, vec[j].Metric) < 0
+	})
+	return vec
+}
+
+// === sort_by_label_desc(vector parser.ValueTypeVector, label parser.ValueTypeString...) Vector ===
+func funcSortByLabelDesc(vals []parser.Value, args parser.Expressions, enh *EvalNodeHelper) Vector {
+	lbls := make([]string, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		lbls = append(lbls, stringFromArg(arg))
+	}
+	vec := vals[0].(Vector)
+	sort.SliceStable(vec, func(i, j int) bool {
+		for _, label := range lbls {
+			if c := compareLabelValues(vec[i].Metric.Get(label), vec[j].Metric.Get(label)); c != 0 {
+				return c > 0
+			}
+		}
+		return labels.Compare(vec[i].Metric, vec[j].Metric) > 0
+	})
+	return vec
+}
+
 // === clamp(Vector parser.ValueTypeVector, min, max Scalar) Vector ===
 func funcClamp(vals []parser.Value, args parser.Expressions, enh *EvalNodeHelper) Vector {
 	vec := vals[0].(Vector)
@@ -966,6 +1022,8 @@ var FunctionCalls = map[string]FunctionCall{
 	"scalar":             funcScalar,
 	"sgn":                funcSgn,
 	"sort":               funcSort,
+	"sort_by_label":      funcSortByLabel,
+	"sort_by_label_desc": funcSortByLabelDesc,
 	"sort_desc":          funcSortDesc,
 	"sqrt":               funcSqrt,
 	"stddev_over_time":   funcStddevOverTime,
@@ -20,8 +20,8 @@ import (
         "strconv"
         "time"
 
-        "github.com/prometheus/prometheus/pkg/labels"
-        "github.com/prometheus/prometheus/pkg/value"
+        "github.com/adolli/prometheus/pkg/labels"
+        "github.com/adolli/prometheus/pkg/value"
 )
 %}
 
@@ -94,6 +94,8 @@ BOTTOMK
 COUNT
 COUNT_VALUES
 GROUP
+LIMITK
+LIMIT_RATIO
 MAX
 MIN
 QUANTILE
@@ -671,7 +673,7 @@ series_value    : IDENTIFIER
  * Keyword lists.
  */
 
-aggregate_op    : AVG | BOTTOMK | COUNT | COUNT_VALUES | GROUP | MAX | MIN | QUANTILE | STDDEV | STDVAR | SUM | TOPK ;
+aggregate_op    : AVG | BOTTOMK | COUNT | COUNT_VALUES | GROUP | LIMITK | LIMIT_RATIO | MAX | MIN | QUANTILE | STDDEV | STDVAR | SUM | TOPK ;
 
 // inside of grouping options label names can be recognized as keywords by the lexer. This is a list of keywords that could also be a label name.
 maybe_label     : AVG | BOOL | BOTTOMK | BY | COUNT | COUNT_VALUES | GROUP | GROUP_LEFT | GROUP_RIGHT | IDENTIFIER | IGNORING | LAND | LOR | LUNLESS | MAX | METRIC_IDENTIFIER | MIN | OFFSET | ON | QUANTILE | STDDEV | STDVAR | SUM | TOPK;
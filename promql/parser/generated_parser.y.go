@@ -80,34 +80,36 @@ const BOTTOMK = 57389
 const COUNT = 57390
 const COUNT_VALUES = 57391
 const GROUP = 57392
-const MAX = 57393
-const MIN = 57394
-const QUANTILE = 57395
-const STDDEV = 57396
-const STDVAR = 57397
-const SUM = 57398
-const TOPK = 57399
-const aggregatorsEnd = 57400
-const keywordsStart = 57401
-const BOOL = 57402
-const BY = 57403
-const GROUP_LEFT = 57404
-const GROUP_RIGHT = 57405
-const IGNORING = 57406
-const OFFSET = 57407
-const ON = 57408
-const WITHOUT = 57409
-const keywordsEnd = 57410
-const preprocessorStart = 57411
-const START = 57412
-const END = 57413
-const preprocessorEnd = 57414
-const startSymbolsStart = 57415
-const START_METRIC = 57416
-const START_SERIES_DESCRIPTION = 57417
-const START_EXPRESSION = 57418
-const START_METRIC_SELECTOR = 57419
-const startSymbolsEnd = 57420
+const LIMITK = 57393
+const LIMIT_RATIO = 57394
+const MAX = 57395
+const MIN = 57396
+const QUANTILE = 57397
+const STDDEV = 57398
+const STDVAR = 57399
+const SUM = 57400
+const TOPK = 57401
+const aggregatorsEnd = 57402
+const keywordsStart = 57403
+const BOOL = 57404
+const BY = 57405
+const GROUP_LEFT = 57406
+const GROUP_RIGHT = 57407
+const IGNORING = 57408
+const OFFSET = 57409
+const ON = 57410
+const WITHOUT = 57411
+const keywordsEnd = 57412
+const preprocessorStart = 57413
+const START = 57414
+const END = 57415
+const preprocessorEnd = 57416
+const startSymbolsStart = 57417
+const START_METRIC = 57418
+const START_SERIES_DESCRIPTION = 57419
+const START_EXPRESSION = 57420
+const START_METRIC_SELECTOR = 57421
+const startSymbolsEnd = 57422
 
 var yyToknames = [...]string{
 	"$end",
@@ -160,6 +162,8 @@ var yyToknames = [...]string{
 	"COUNT",
 	"COUNT_VALUES",
 	"GROUP",
+	"LIMITK",
+	"LIMIT_RATIO",
 	"MAX",
 	"MIN",
 	"QUANTILE",
@@ -196,10 +200,10 @@ const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line generated_parser.y:747
+//line generated_parser.y:749
 
 //line yacctab:1
-var yyExca = [...]int{
+var yyExca = [...]int16{
 	-1, 1,
 	1, -1,
 	-2, 0,
@@ -211,247 +215,256 @@ var yyExca = [...]int{
 	-1, 56,
 	2, 140,
 	15, 140,
-	61, 140,
-	67, 140,
+	63, 140,
+	69, 140,
 	-2, 96,
 	-1, 57,
 	2, 141,
 	15, 141,
-	61, 141,
-	67, 141,
+	63, 141,
+	69, 141,
 	-2, 97,
 	-1, 58,
 	2, 142,
 	15, 142,
-	61, 142,
-	67, 142,
+	63, 142,
+	69, 142,
 	-2, 99,
 	-1, 59,
 	2, 143,
 	15, 143,
-	61, 143,
-	67, 143,
+	63, 143,
+	69, 143,
 	-2, 100,
 	-1, 60,
 	2, 144,
 	15, 144,
-	61, 144,
-	67, 144,
+	63, 144,
+	69, 144,
 	-2, 101,
-	-1, 61,
-	2, 145,
-	15, 145,
-	61, 145,
-	67, 145,
-	-2, 106,
-	-1, 62,
-	2, 146,
-	15, 146,
-	61, 146,
-	67, 146,
-	-2, 108,
 	-1, 63,
 	2, 147,
 	15, 147,
-	61, 147,
-	67, 147,
-	-2, 110,
+	63, 147,
+	69, 147,
+	-2, 106,
 	-1, 64,
 	2, 148,
 	15, 148,
-	61, 148,
-	67, 148,
-	-2, 111,
+	63, 148,
+	69, 148,
+	-2, 108,
 	-1, 65,
 	2, 149,
 	15, 149,
-	61, 149,
-	67, 149,
-	-2, 112,
+	63, 149,
+	69, 149,
+	-2, 110,
 	-1, 66,
 	2, 150,
 	15, 150,
-	61, 150,
-	67, 150,
-	-2, 113,
+	63, 150,
+	69, 150,
+	-2, 111,
 	-1, 67,
 	2, 151,
 	15, 151,
-	61, 151,
-	67, 151,
+	63, 151,
+	69, 151,
+	-2, 112,
+	-1, 68,
+	2, 152,
+	15, 152,
+	63, 152,
+	69, 152,
+	-2, 113,
+	-1, 69,
+	2, 153,
+	15, 153,
+	63, 153,
+	69, 153,
 	-2, 114,
-	-1, 186,
-	12, 193,
-	13, 193,
-	16, 193,
-	17, 193,
-	23, 193,
-	26, 193,
-	32, 193,
-	33, 193,
-	36, 193,
-	42, 193,
-	46, 193,
-	47, 193,
-	48, 193,
-	49, 193,
-	50, 193,
-	51, 193,
-	52, 193,
-	53, 193,
-	54, 193,
-	55, 193,
-	56, 193,
-	57, 193,
-	61, 193,
-	65, 193,
-	67, 193,
+	-1, 188,
+	12, 195,
+	13, 195,
+	16, 195,
+	17, 195,
+	23, 195,
+	26, 195,
+	32, 195,
+	33, 195,
+	36, 195,
+	42, 195,
+	46, 195,
+	47, 195,
+	48, 195,
+	49, 195,
+	50, 195,
+	51, 195,
+	52, 195,
+	53, 195,
+	54, 195,
+	55, 195,
+	56, 195,
+	57, 195,
+	58, 195,
+	59, 195,
+	63, 195,
+	67, 195,
+	69, 195,
 	-2, 0,
-	-1, 187,
-	12, 193,
-	13, 193,
-	16, 193,
-	17, 193,
-	23, 193,
-	26, 193,
-	32, 193,
-	33, 193,
-	36, 193,
-	42, 193,
-	46, 193,
-	47, 193,
-	48, 193,
-	49, 193,
-	50, 193,
-	51, 193,
-	52, 193,
-	53, 193,
-	54, 193,
-	55, 193,
-	56, 193,
-	57, 193,
-	61, 193,
-	65, 193,
-	67, 193,
+	-1, 189,
+	12, 195,
+	13, 195,
+	16, 195,
+	17, 195,
+	23, 195,
+	26, 195,
+	32, 195,
+	33, 195,
+	36, 195,
+	42, 195,
+	46, 195,
+	47, 195,
+	48, 195,
+	49, 195,
+	50, 195,
+	51, 195,
+	52, 195,
+	53, 195,
+	54, 195,
+	55, 195,
+	56, 195,
+	57, 195,
+	58, 195,
+	59, 195,
+	63, 195,
+	67, 195,
+	69, 195,
 	-2, 0,
-	-1, 207,
-	19, 191,
+	-1, 209,
+	19, 193,
 	-2, 0,
-	-1, 254,
-	19, 192,
+	-1, 256,
+	19, 194,
 	-2, 0,
 }
 
 const yyPrivate = 57344
 
-const yyLast = 638
-
-var yyAct = [...]int{
-	260, 35, 211, 138, 250, 249, 146, 110, 75, 99,
-	98, 101, 144, 184, 6, 185, 123, 102, 140, 100,
-	186, 187, 55, 145, 245, 141, 150, 149, 263, 244,
-	49, 70, 103, 51, 22, 50, 150, 118, 161, 252,
-	243, 52, 151, 239, 68, 264, 261, 112, 149, 203,
-	18, 19, 151, 105, 20, 106, 238, 111, 139, 104,
-	69, 242, 119, 240, 56, 57, 58, 59, 60, 61,
-	62, 63, 64, 65, 66, 67, 176, 107, 101, 13,
-	147, 148, 158, 24, 102, 30, 2, 3, 4, 5,
-	97, 258, 103, 7, 208, 157, 257, 175, 207, 166,
-	70, 152, 80, 81, 165, 162, 156, 159, 154, 256,
-	155, 206, 31, 90, 91, 164, 141, 93, 94, 96,
-	117, 183, 116, 174, 265, 182, 188, 189, 190, 191,
+const yyLast = 673
+
+var yyAct = [...]int16{
+	262, 35, 213, 140, 252, 251, 148, 112, 77, 101,
+	100, 103, 146, 186, 6, 187, 125, 104, 265, 102,
+	188, 189, 246, 147, 55, 245, 178, 151, 120, 254,
+	49, 72, 105, 51, 22, 50, 152, 205, 163, 266,
+	247, 52, 152, 260, 70, 263, 244, 177, 259, 151,
+	18, 19, 153, 107, 20, 108, 142, 105, 153, 106,
+	71, 258, 121, 143, 56, 57, 58, 59, 60, 61,
+	62, 63, 64, 65, 66, 67, 68, 69, 72, 109,
+	103, 13, 149, 150, 241, 24, 104, 30, 2, 3,
+	4, 5, 31, 7, 210, 257, 141, 240, 209, 143,
+	160, 168, 143, 154, 114, 79, 167, 164, 158, 161,
+	156, 208, 157, 159, 113, 78, 46, 166, 267, 155,
+	242, 175, 73, 185, 81, 176, 143, 184, 190, 191,
 	192, 193, 194, 195, 196, 197, 198, 199, 200, 201,
-	79, 95, 181, 202, 124, 125, 126, 127, 128, 129,
-	130, 131, 132, 133, 134, 135, 136, 137, 255, 10,
-	168, 77, 169, 153, 54, 141, 112, 9, 9, 72,
-	141, 76, 32, 241, 204, 205, 111, 173, 34, 97,
-	49, 70, 109, 51, 22, 50, 246, 171, 1, 247,
-	248, 52, 81, 251, 68, 8, 253, 170, 172, 33,
-	18, 19, 90, 91, 20, 97, 93, 46, 96, 45,
-	69, 254, 44, 71, 56, 57, 58, 59, 60, 61,
-	62, 63, 64, 65, 66, 67, 43, 77, 42, 13,
-	95, 122, 93, 24, 96, 30, 41, 76, 40, 39,
-	120, 259, 178, 74, 160, 38, 262, 49, 70, 180,
-	51, 22, 50, 121, 149, 37, 95, 115, 52, 36,
-	267, 68, 114, 150, 268, 47, 142, 18, 19, 179,
-	78, 20, 177, 113, 209, 73, 143, 69, 53, 151,
-	212, 56, 57, 58, 59, 60, 61, 62, 63, 64,
-	65, 66, 67, 213, 167, 48, 13, 108, 0, 0,
-	24, 0, 30, 223, 0, 0, 0, 229, 0, 0,
-	0, 266, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 225, 226, 0, 0, 227, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 214, 216, 218,
-	219, 220, 228, 230, 233, 234, 235, 236, 237, 213,
-	0, 215, 217, 221, 222, 224, 231, 232, 0, 223,
-	0, 0, 0, 229, 0, 0, 0, 210, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 225,
-	226, 0, 0, 227, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 214, 216, 218, 219, 220, 228, 230,
-	233, 234, 235, 236, 237, 0, 0, 215, 217, 221,
-	222, 224, 231, 232, 17, 70, 0, 0, 22, 0,
+	202, 203, 32, 1, 183, 204, 126, 127, 128, 129,
+	130, 131, 132, 133, 134, 135, 136, 137, 138, 139,
+	10, 255, 170, 117, 171, 119, 45, 118, 116, 54,
+	74, 44, 9, 9, 114, 243, 206, 207, 43, 115,
+	34, 99, 49, 72, 113, 51, 22, 50, 248, 173,
+	111, 249, 250, 52, 83, 253, 70, 8, 42, 172,
+	174, 33, 18, 19, 92, 93, 20, 124, 95, 41,
+	98, 99, 71, 256, 40, 39, 56, 57, 58, 59,
+	60, 61, 62, 63, 64, 65, 66, 67, 68, 69,
+	122, 162, 38, 13, 97, 180, 123, 24, 95, 30,
+	98, 79, 182, 261, 37, 36, 47, 151, 264, 49,
+	72, 78, 51, 22, 50, 144, 152, 76, 181, 80,
+	52, 179, 269, 70, 97, 211, 270, 75, 145, 18,
+	19, 53, 153, 20, 214, 169, 48, 110, 0, 71,
+	0, 0, 0, 56, 57, 58, 59, 60, 61, 62,
+	63, 64, 65, 66, 67, 68, 69, 215, 0, 0,
+	13, 0, 0, 0, 24, 0, 30, 225, 0, 0,
+	0, 231, 0, 0, 0, 268, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 227, 228, 0,
+	0, 229, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 216, 218, 220, 221, 222, 0, 0, 230, 232,
+	235, 236, 237, 238, 239, 215, 0, 217, 219, 223,
+	224, 226, 233, 234, 0, 225, 0, 0, 0, 231,
+	0, 0, 0, 212, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 227, 228, 0, 0, 229,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 216,
+	218, 220, 221, 222, 0, 0, 230, 232, 235, 236,
+	237, 238, 239, 0, 0, 217, 219, 223, 224, 226,
+	233, 234, 17, 72, 0, 0, 22, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 18, 19, 0, 0, 20, 0,
-	17, 31, 0, 0, 22, 0, 0, 0, 11, 12,
-	14, 15, 16, 21, 23, 25, 26, 27, 28, 29,
+	0, 0, 18, 19, 0, 0, 20, 0, 0, 0,
+	17, 31, 0, 0, 22, 0, 11, 12, 14, 15,
+	16, 0, 0, 21, 23, 25, 26, 27, 28, 29,
 	18, 19, 0, 13, 20, 0, 0, 24, 0, 30,
-	0, 0, 0, 0, 11, 12, 14, 15, 16, 21,
-	23, 25, 26, 27, 28, 29, 97, 0, 0, 13,
-	0, 0, 163, 24, 0, 30, 0, 0, 80, 81,
-	82, 0, 83, 84, 85, 86, 87, 88, 89, 90,
-	91, 92, 0, 93, 94, 96, 0, 0, 0, 0,
-	0, 0, 97, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 80, 81, 82, 95, 83, 84,
-	85, 86, 87, 88, 89, 90, 91, 92, 0, 93,
-	94, 96, 0, 0, 97, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 80, 81, 82, 0,
-	83, 84, 85, 95, 87, 88, 89, 90, 91, 92,
-	0, 93, 94, 96, 0, 0, 97, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 80, 81,
-	82, 0, 83, 84, 0, 95, 87, 88, 0, 90,
-	91, 92, 0, 93, 94, 96, 0, 0, 0, 0,
+	0, 0, 0, 0, 11, 12, 14, 15, 16, 0,
+	0, 21, 23, 25, 26, 27, 28, 29, 99, 0,
+	0, 13, 0, 0, 165, 24, 0, 30, 0, 0,
+	82, 83, 84, 0, 85, 86, 87, 88, 89, 90,
+	91, 92, 93, 94, 0, 95, 96, 98, 0, 0,
+	0, 0, 0, 0, 0, 0, 99, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 82, 83,
+	84, 97, 85, 86, 87, 88, 89, 90, 91, 92,
+	93, 94, 0, 95, 96, 98, 0, 0, 0, 0,
+	99, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 82, 83, 84, 0, 85, 86, 87, 97,
+	89, 90, 91, 92, 93, 94, 0, 95, 96, 98,
+	0, 99, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 82, 83, 84, 0, 85, 86, 99,
+	0, 89, 90, 97, 92, 93, 94, 0, 95, 96,
+	98, 82, 83, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 92, 93, 0, 0, 95, 96, 98, 0,
+	0, 0, 0, 0, 97, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 95,
+	0, 0, 97,
 }
 
-var yyPact = [...]int{
-	12, 83, 428, 428, 168, 402, -1000, -1000, -1000, 99,
+var yyPact = [...]int16{
+	12, 83, 438, 438, 170, 410, -1000, -1000, -1000, 79,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 225, -1000, 138, -1000, 508, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 17, 77,
-	-1000, 235, -1000, 235, 87, -1000, -1000, -1000, -1000, -1000,
+	-1000, 239, -1000, 122, -1000, 522, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 17, 42,
+	-1000, 237, -1000, 237, 65, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	164, -1000, -1000, 255, -1000, -1000, 118, -1000, 15, -1000,
-	-44, -44, -44, -44, -44, -44, -44, -44, -44, -44,
-	-44, -44, -44, -44, -44, 16, 10, 161, 77, -50,
-	-1000, 80, 80, 18, -1000, 472, 191, -1000, 97, -1000,
-	-1000, 158, -1000, -1000, 159, -1000, 74, -1000, 237, 235,
-	-1000, -51, -42, -1000, 235, 235, 235, 235, 235, 235,
-	235, 235, 235, 235, 235, 235, 235, 235, -1000, 107,
-	-1000, -1000, -1000, 34, -1000, -1000, -1000, -1000, -1000, -1000,
-	31, 31, 92, -1000, -1000, -1000, -1000, 347, -1000, -1000,
-	36, -1000, 508, -1000, -1000, 45, -1000, 38, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 5, 0,
-	-1000, -1000, -1000, 165, 80, 80, 80, 80, 191, 76,
-	76, 76, 572, 540, 76, 76, 572, 191, 191, 76,
-	191, 165, -1000, 19, -1000, -1000, -1000, 156, -1000, 89,
+	-1000, -1000, 172, -1000, -1000, 161, -1000, -1000, 163, -1000,
+	6, -1000, -46, -46, -46, -46, -46, -46, -46, -46,
+	-46, -46, -46, -46, -46, -46, -46, 54, 10, 117,
+	42, -52, -1000, 98, 98, 18, -1000, 484, 197, -1000,
+	99, -1000, -1000, 160, -1000, -1000, 103, -1000, 24, -1000,
+	230, 237, -1000, -53, -44, -1000, 237, 237, 237, 237,
+	237, 237, 237, 237, 237, 237, 237, 237, 237, 237,
+	-1000, 90, -1000, -1000, -1000, 22, -1000, -1000, -1000, -1000,
+	-1000, -1000, 32, 32, 92, -1000, -1000, -1000, -1000, 353,
+	-1000, -1000, 77, -1000, 522, -1000, -1000, 102, -1000, 23,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-2, 16, -1000, -1000, -1000, 167, 98, 98, 98, 98,
+	197, 605, 605, 605, 587, 556, 605, 605, 587, 197,
+	197, 605, 197, 167, -1000, 9, -1000, -1000, -1000, 93,
+	-1000, 41, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 235,
-	-1000, -1000, -1000, -1000, 29, 29, 4, -1000, -1000, -1000,
-	-1000, -1000, -1000, 26, 122, -1000, -1000, 291, -1000, 508,
-	-1000, -1000, -1000, 29, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, 237, -1000, -1000, -1000, -1000, 28, 28, -6, -1000,
+	-1000, -1000, -1000, -1000, -1000, 20, 116, -1000, -1000, 295,
+	-1000, 522, -1000, -1000, -1000, 28, -1000, -1000, -1000, -1000,
+	-1000,
 }
 
-var yyPgo = [...]int{
-	0, 297, 7, 295, 2, 294, 280, 164, 278, 276,
-	159, 275, 195, 8, 274, 4, 5, 272, 270, 0,
-	23, 269, 6, 266, 265, 259, 10, 62, 255, 253,
-	1, 245, 244, 9, 240, 22, 239, 238, 236, 231,
-	228, 226, 212, 209, 207, 3, 196, 188, 172,
+var yyPgo = [...]int16{
+	0, 277, 7, 276, 2, 275, 274, 169, 271, 268,
+	160, 267, 197, 8, 265, 4, 5, 261, 259, 0,
+	23, 258, 6, 255, 246, 245, 10, 62, 244, 236,
+	1, 232, 231, 9, 230, 24, 215, 214, 209, 207,
+	198, 178, 171, 166, 116, 3, 161, 143, 142,
 }
 
-var yyR1 = [...]int{
+var yyR1 = [...]int8{
 	0, 47, 47, 47, 47, 47, 47, 47, 30, 30,
 	30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
 	25, 25, 25, 25, 26, 26, 28, 28, 28, 28,
@@ -467,14 +480,14 @@ var yyR1 = [...]int{
 	11, 11, 11, 13, 13, 13, 13, 48, 18, 18,
 	18, 18, 17, 17, 17, 17, 17, 21, 21, 21,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 6, 6, 6, 6, 6, 6, 6, 6,
+	3, 3, 3, 3, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
-	6, 6, 6, 6, 6, 6, 8, 8, 5, 5,
-	5, 5, 37, 20, 22, 22, 23, 23, 19, 45,
-	41, 46, 46, 16, 16,
+	6, 6, 6, 6, 6, 6, 6, 6, 8, 8,
+	5, 5, 5, 5, 37, 20, 22, 22, 23, 23,
+	19, 45, 41, 46, 46, 16, 16,
 }
 
-var yyR2 = [...]int{
+var yyR2 = [...]int8{
 	0, 2, 2, 2, 2, 2, 2, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	3, 3, 2, 2, 2, 2, 4, 4, 4, 4,
@@ -493,75 +506,77 @@ var yyR2 = [...]int{
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 2, 2, 1, 1, 1, 1,
-	1, 0, 1, 0, 1,
+	1, 1, 1, 1, 1, 1, 2, 2, 1, 1,
+	1, 1, 1, 0, 1, 0, 1,
 }
 
-var yyChk = [...]int{
-	-1000, -47, 74, 75, 76, 77, 2, 10, -12, -7,
-	-10, 46, 47, 61, 48, 49, 50, 12, 32, 33,
-	36, 51, 16, 52, 65, 53, 54, 55, 56, 57,
-	67, 13, -48, -12, 10, -30, -25, -28, -31, -36,
+var yyChk = [...]int16{
+	-1000, -47, 76, 77, 78, 79, 2, 10, -12, -7,
+	-10, 46, 47, 63, 48, 49, 50, 12, 32, 33,
+	36, 53, 16, 54, 67, 55, 56, 57, 58, 59,
+	69, 13, -48, -12, 10, -30, -25, -28, -31, -36,
 	-37, -38, -40, -41, -42, -43, -44, -24, -3, 12,
 	17, 15, 23, -8, -7, -35, 46, 47, 48, 49,
-	50, 51, 52, 53, 54, 55, 56, 57, 26, 42,
-	13, -44, -10, -11, 18, -13, 12, 2, -18, 2,
-	26, 27, 28, 30, 31, 32, 33, 34, 35, 36,
-	37, 38, 39, 41, 42, 65, 43, 14, -26, -33,
-	2, 61, 67, 15, -33, -30, -30, -35, -1, 18,
-	-2, 12, 2, 18, 7, 2, 4, 2, 22, -27,
-	-34, -29, -39, 60, -27, -27, -27, -27, -27, -27,
-	-27, -27, -27, -27, -27, -27, -27, -27, -45, 42,
-	2, 9, -23, -9, 2, -20, -22, 70, 71, 17,
-	26, 42, -45, 2, -33, -26, -15, 15, 2, -15,
-	-32, 20, -30, 20, 18, 7, 2, -5, 2, 4,
-	39, 29, 40, 18, -13, 23, 2, -17, 5, -21,
-	12, -20, -22, -30, 64, 66, 62, 63, -30, -30,
+	50, 51, 52, 53, 54, 55, 56, 57, 58, 59,
+	26, 42, 13, -44, -10, -11, 18, -13, 12, 2,
+	-18, 2, 26, 27, 28, 30, 31, 32, 33, 34,
+	35, 36, 37, 38, 39, 41, 42, 67, 43, 14,
+	-26, -33, 2, 63, 69, 15, -33, -30, -30, -35,
+	-1, 18, -2, 12, 2, 18, 7, 2, 4, 2,
+	22, -27, -34, -29, -39, 62, -27, -27, -27, -27,
+	-27, -27, -27, -27, -27, -27, -27, -27, -27, -27,
+	-45, 42, 2, 9, -23, -9, 2, -20, -22, 72,
+	73, 17, 26, 42, -45, 2, -33, -26, -15, 15,
+	2, -15, -32, 20, -30, 20, 18, 7, 2, -5,
+	2, 4, 39, 29, 40, 18, -13, 23, 2, -17,
+	5, -21, 12, -20, -22, -30, 66, 68, 64, 65,
 	-30, -30, -30, -30, -30, -30, -30, -30, -30, -30,
-	-30, -30, -45, 15, -20, -20, 19, 6, 2, -14,
-	20, -4, -6, 2, 46, 60, 47, 61, 48, 49,
-	50, 62, 63, 12, 64, 32, 33, 36, 51, 16,
-	52, 65, 66, 53, 54, 55, 56, 57, 20, 7,
-	18, -2, 23, 2, 24, 24, -22, -15, -15, -16,
-	-15, -16, 20, -46, -45, 2, 20, 7, 2, -30,
-	-19, 17, -19, 24, 19, 2, 20, -4, -19,
+	-30, -30, -30, -30, -45, 15, -20, -20, 19, 6,
+	2, -14, 20, -4, -6, 2, 46, 62, 47, 63,
+	48, 49, 50, 64, 65, 12, 66, 32, 33, 36,
+	53, 16, 54, 67, 68, 55, 56, 57, 58, 59,
+	20, 7, 18, -2, 23, 2, 24, 24, -22, -15,
+	-15, -16, -15, -16, 20, -46, -45, 2, 20, 7,
+	2, -30, -19, 17, -19, 24, 19, 2, 20, -4,
+	-19,
 }
 
-var yyDef = [...]int{
+var yyDef = [...]int16{
 	0, -2, 119, 119, 0, 0, 7, 6, 1, 119,
 	95, 96, 97, 98, 99, 100, 101, 102, 103, 104,
 	105, 106, 107, 108, 109, 110, 111, 112, 113, 114,
 	115, 0, 2, -2, 3, 4, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 0, 102,
-	182, 0, 190, 0, 82, 83, -2, -2, -2, -2,
-	-2, -2, -2, -2, -2, -2, -2, -2, 176, 177,
-	0, 5, 94, 0, 118, 121, 0, 126, 127, 131,
-	42, 42, 42, 42, 42, 42, 42, 42, 42, 42,
-	42, 42, 42, 42, 42, 0, 0, 0, 0, 22,
-	23, 0, 0, 0, 59, 0, 80, 81, 0, 86,
-	88, 0, 93, 116, 0, 122, 0, 125, 130, 0,
-	41, 46, 47, 43, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 66, 0,
-	68, 189, 69, 0, 71, 186, 187, 72, 73, 183,
-	0, 0, 0, 79, 20, 21, 24, 0, 53, 25,
-	0, 61, 63, 65, 84, 0, 89, 0, 92, 178,
-	179, 180, 181, 117, 120, 123, 124, 129, 132, 134,
-	137, 138, 139, 26, 0, 0, -2, -2, 27, 28,
-	29, 30, 31, 32, 33, 34, 35, 36, 37, 38,
-	39, 40, 67, 0, 184, 185, 74, -2, 78, 0,
-	52, 55, 57, 58, 152, 153, 154, 155, 156, 157,
+	184, 0, 192, 0, 82, 83, -2, -2, -2, -2,
+	-2, 145, 146, -2, -2, -2, -2, -2, -2, -2,
+	178, 179, 0, 5, 94, 0, 118, 121, 0, 126,
+	127, 131, 42, 42, 42, 42, 42, 42, 42, 42,
+	42, 42, 42, 42, 42, 42, 42, 0, 0, 0,
+	0, 22, 23, 0, 0, 0, 59, 0, 80, 81,
+	0, 86, 88, 0, 93, 116, 0, 122, 0, 125,
+	130, 0, 41, 46, 47, 43, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	66, 0, 68, 191, 69, 0, 71, 188, 189, 72,
+	73, 185, 0, 0, 0, 79, 20, 21, 24, 0,
+	53, 25, 0, 61, 63, 65, 84, 0, 89, 0,
+	92, 180, 181, 182, 183, 117, 120, 123, 124, 129,
+	132, 134, 137, 138, 139, 26, 0, 0, -2, -2,
+	27, 28, 29, 30, 31, 32, 33, 34, 35, 36,
+	37, 38, 39, 40, 67, 0, 186, 187, 74, -2,
+	78, 0, 52, 55, 57, 58, 154, 155, 156, 157,
 	158, 159, 160, 161, 162, 163, 164, 165, 166, 167,
-	168, 169, 170, 171, 172, 173, 174, 175, 60, 64,
-	85, 87, 90, 91, 0, 0, 0, 44, 45, 48,
-	194, 49, 70, 0, -2, 77, 50, 0, 56, 62,
-	133, 188, 135, 0, 75, 76, 51, 54, 136,
+	168, 169, 170, 171, 172, 173, 174, 175, 176, 177,
+	60, 64, 85, 87, 90, 91, 0, 0, 0, 44,
+	45, 48, 196, 49, 70, 0, -2, 77, 50, 0,
+	56, 62, 133, 190, 135, 0, 75, 76, 51, 54,
+	136,
 }
 
-var yyTok1 = [...]int{
+var yyTok1 = [...]int8{
 	1,
 }
 
-var yyTok2 = [...]int{
+var yyTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
@@ -569,10 +584,10 @@ var yyTok2 = [...]int{
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
 	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
-	72, 73, 74, 75, 76, 77, 78,
+	72, 73, 74, 75, 76, 77, 78, 79, 80,
 }
 
-var yyTok3 = [...]int{
+var yyTok3 = [...]int8{
 	0,
 }
 
@@ -654,9 +669,9 @@ func yyErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := yyPact[state]
+	base := int(yyPact[state])
 	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
-		if n := base + tok; n >= 0 && n < yyLast && yyChk[yyAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < yyLast && int(yyChk[int(yyAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -666,13 +681,13 @@ func yyErrorMessage(state, lookAhead int) string {
 
 	if yyDef[state] == -2 {
 		i := 0
-		for yyExca[i] != -1 || yyExca[i+1] != state {
+		for yyExca[i] != -1 || int(yyExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; yyExca[i] >= 0; i += 2 {
-			tok := yyExca[i]
+			tok := int(yyExca[i])
 			if tok < TOKSTART || yyExca[i+1] == 0 {
 				continue
 			}
@@ -703,30 +718,30 @@ func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = yyTok1[0]
+		token = int(yyTok1[0])
 		goto out
 	}
 	if char < len(yyTok1) {
-		token = yyTok1[char]
+		token = int(yyTok1[char])
 		goto out
 	}
 	if char >= yyPrivate {
 		if char < yyPrivate+len(yyTok2) {
-			token = yyTok2[char-yyPrivate]
+			token = int(yyTok2[char-yyPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(yyTok3); i += 2 {
-		token = yyTok3[i+0]
+		token = int(yyTok3[i+0])
 		if token == char {
-			token = yyTok3[i+1]
+			token = int(yyTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = yyTok2[1] /* unknown char */
+		token = int(yyTok2[1]) /* unknown char */
 	}
 	if yyDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
@@ -781,7 +796,7 @@ yystack:
 	yyS[yyp].yys = yystate
 
 yynewstate:
-	yyn = yyPact[yystate]
+	yyn = int(yyPact[yystate])
 	if yyn <= yyFlag {
 		goto yydefault /* simple state */
 	}
@@ -792,8 +807,8 @@ yynewstate:
 	if yyn < 0 || yyn >= yyLast {
 		goto yydefault
 	}
-	yyn = yyAct[yyn]
-	if yyChk[yyn] == yytoken { /* valid shift */
+	yyn = int(yyAct[yyn])
+	if int(yyChk[yyn]) == yytoken { /* valid shift */
 		yyrcvr.char = -1
 		yytoken = -1
 		yyVAL = yyrcvr.lval
@@ -806,7 +821,7 @@ yynewstate:
 
 yydefault:
 	/* default state action */
-	yyn = yyDef[yystate]
+	yyn = int(yyDef[yystate])
 	if yyn == -2 {
 		if yyrcvr.char < 0 {
 			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
@@ -815,18 +830,18 @@ yydefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if yyExca[xi+0] == -1 && yyExca[xi+1] == yystate {
+			if yyExca[xi+0] == -1 && int(yyExca[xi+1]) == yystate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			yyn = yyExca[xi+0]
+			yyn = int(yyExca[xi+0])
 			if yyn < 0 || yyn == yytoken {
 				break
 			}
 		}
-		yyn = yyExca[xi+1]
+		yyn = int(yyExca[xi+1])
 		if yyn < 0 {
 			goto ret0
 		}
@@ -848,10 +863,10 @@ yydefault:
 
 			/* find a state where "error" is a legal shift action */
 			for yyp >= 0 {
-				yyn = yyPact[yyS[yyp].yys] + yyErrCode
+				yyn = int(yyPact[yyS[yyp].yys]) + yyErrCode
 				if yyn >= 0 && yyn < yyLast {
-					yystate = yyAct[yyn] /* simulate a shift of "error" */
-					if yyChk[yystate] == yyErrCode {
+					yystate = int(yyAct[yyn]) /* simulate a shift of "error" */
+					if int(yyChk[yystate]) == yyErrCode {
 						goto yystack
 					}
 				}
@@ -887,7 +902,7 @@ yydefault:
 	yypt := yyp
 	_ = yypt // guard against "declared and not used"
 
-	yyp -= yyR2[yyn]
+	yyp -= int(yyR2[yyn])
 	// yyp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if yyp+1 >= len(yyS) {
@@ -898,16 +913,16 @@ yydefault:
 	yyVAL = yyS[yyp+1]
 
 	/* consult goto table to find next state */
-	yyn = yyR1[yyn]
-	yyg := yyPgo[yyn]
+	yyn = int(yyR1[yyn])
+	yyg := int(yyPgo[yyn])
 	yyj := yyg + yyS[yyp].yys + 1
 
 	if yyj >= yyLast {
-		yystate = yyAct[yyg]
+		yystate = int(yyAct[yyg])
 	} else {
-		yystate = yyAct[yyj]
-		if yyChk[yystate] != -yyn {
-			yystate = yyAct[yyg]
+		yystate = int(yyAct[yyj])
+		if int(yyChk[yystate]) != -yyn {
+			yystate = int(yyAct[yyg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -915,62 +930,62 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:173
+//line generated_parser.y:175
 		{
 			yylex.(*parser).generatedParserResult = yyDollar[2].labels
 		}
 	case 3:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:176
+//line generated_parser.y:178
 		{
 			yylex.(*parser).addParseErrf(PositionRange{}, "no expression found in input")
 		}
 	case 4:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:178
+//line generated_parser.y:180
 		{
 			yylex.(*parser).generatedParserResult = yyDollar[2].node
 		}
 	case 5:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:180
+//line generated_parser.y:182
 		{
 			yylex.(*parser).generatedParserResult = yyDollar[2].node
 		}
 	case 7:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:183
+//line generated_parser.y:185
 		{
 			yylex.(*parser).unexpected("", "")
 		}
 	case 20:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:206
+//line generated_parser.y:208
 		{
 			yyVAL.node = yylex.(*parser).newAggregateExpr(yyDollar[1].item, yyDollar[2].node, yyDollar[3].node)
 		}
 	case 21:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:208
+//line generated_parser.y:210
 		{
 			yyVAL.node = yylex.(*parser).newAggregateExpr(yyDollar[1].item, yyDollar[3].node, yyDollar[2].node)
 		}
 	case 22:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:210
+//line generated_parser.y:212
 		{
 			yyVAL.node = yylex.(*parser).newAggregateExpr(yyDollar[1].item, &AggregateExpr{}, yyDollar[2].node)
 		}
 	case 23:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:212
+//line generated_parser.y:214
 		{
 			yylex.(*parser).unexpected("aggregation", "")
 			yyVAL.node = yylex.(*parser).newAggregateExpr(yyDollar[1].item, &AggregateExpr{}, Expressions{})
 		}
 	case 24:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:220
+//line generated_parser.y:222
 		{
 			yyVAL.node = &AggregateExpr{
 				Grouping: yyDollar[2].strings,
@@ -978,7 +993,7 @@ yydefault:
 		}
 	case 25:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:226
+//line generated_parser.y:228
 		{
 			yyVAL.node = &AggregateExpr{
 				Grouping: yyDollar[2].strings,
@@ -987,97 +1002,97 @@ yydefault:
 		}
 	case 26:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:239
+//line generated_parser.y:241
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 27:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:240
+//line generated_parser.y:242
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 28:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:241
+//line generated_parser.y:243
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 29:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:242
+//line generated_parser.y:244
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 30:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:243
+//line generated_parser.y:245
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 31:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:244
+//line generated_parser.y:246
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 32:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:245
+//line generated_parser.y:247
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 33:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:246
+//line generated_parser.y:248
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 34:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:247
+//line generated_parser.y:249
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 35:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:248
+//line generated_parser.y:250
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 36:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:249
+//line generated_parser.y:251
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 37:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:250
+//line generated_parser.y:252
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 38:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:251
+//line generated_parser.y:253
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 39:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:252
+//line generated_parser.y:254
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 40:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:253
+//line generated_parser.y:255
 		{
 			yyVAL.node = yylex.(*parser).newBinaryExpression(yyDollar[1].node, yyDollar[2].item, yyDollar[3].node, yyDollar[4].node)
 		}
 	case 42:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line generated_parser.y:261
+//line generated_parser.y:263
 		{
 			yyVAL.node = &BinaryExpr{
 				VectorMatching: &VectorMatching{Card: CardOneToOne},
@@ -1085,7 +1100,7 @@ yydefault:
 		}
 	case 43:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:266
+//line generated_parser.y:268
 		{
 			yyVAL.node = &BinaryExpr{
 				VectorMatching: &VectorMatching{Card: CardOneToOne},
@@ -1094,14 +1109,14 @@ yydefault:
 		}
 	case 44:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:274
+//line generated_parser.y:276
 		{
 			yyVAL.node = yyDollar[1].node
 			yyVAL.node.(*BinaryExpr).VectorMatching.MatchingLabels = yyDollar[3].strings
 		}
 	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:279
+//line generated_parser.y:281
 		{
 			yyVAL.node = yyDollar[1].node
 			yyVAL.node.(*BinaryExpr).VectorMatching.MatchingLabels = yyDollar[3].strings
@@ -1109,7 +1124,7 @@ yydefault:
 		}
 	case 48:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:289
+//line generated_parser.y:291
 		{
 			yyVAL.node = yyDollar[1].node
 			yyVAL.node.(*BinaryExpr).VectorMatching.Card = CardManyToOne
@@ -1117,7 +1132,7 @@ yydefault:
 		}
 	case 49:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:295
+//line generated_parser.y:297
 		{
 			yyVAL.node = yyDollar[1].node
 			yyVAL.node.(*BinaryExpr).VectorMatching.Card = CardOneToMany
@@ -1125,51 +1140,51 @@ yydefault:
 		}
 	case 50:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:304
+//line generated_parser.y:306
 		{
 			yyVAL.strings = yyDollar[2].strings
 		}
 	case 51:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:306
+//line generated_parser.y:308
 		{
 			yyVAL.strings = yyDollar[2].strings
 		}
 	case 52:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:308
+//line generated_parser.y:310
 		{
 			yyVAL.strings = []string{}
 		}
 	case 53:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:310
+//line generated_parser.y:312
 		{
 			yylex.(*parser).unexpected("grouping opts", "\"(\"")
 			yyVAL.strings = nil
 		}
 	case 54:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:316
+//line generated_parser.y:318
 		{
 			yyVAL.strings = append(yyDollar[1].strings, yyDollar[3].item.Val)
 		}
 	case 55:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:318
+//line generated_parser.y:320
 		{
 			yyVAL.strings = []string{yyDollar[1].item.Val}
 		}
 	case 56:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:320
+//line generated_parser.y:322
 		{
 			yylex.(*parser).unexpected("grouping opts", "\",\" or \")\"")
 			yyVAL.strings = yyDollar[1].strings
 		}
 	case 57:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:324
+//line generated_parser.y:326
 		{
 			if !isLabel(yyDollar[1].item.Val) {
 				yylex.(*parser).unexpected("grouping opts", "label")
@@ -1178,14 +1193,14 @@ yydefault:
 		}
 	case 58:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:331
+//line generated_parser.y:333
 		{
 			yylex.(*parser).unexpected("grouping opts", "label")
 			yyVAL.item = Item{}
 		}
 	case 59:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:339
+//line generated_parser.y:341
 		{
 			fn, exist := getFunction(yyDollar[1].item.Val)
 			if !exist {
@@ -1202,86 +1217,86 @@ yydefault:
 		}
 	case 60:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:356
+//line generated_parser.y:358
 		{
 			yyVAL.node = yyDollar[2].node
 		}
 	case 61:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:358
+//line generated_parser.y:360
 		{
 			yyVAL.node = Expressions{}
 		}
 	case 62:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:362
+//line generated_parser.y:364
 		{
 			yyVAL.node = append(yyDollar[1].node.(Expressions), yyDollar[3].node.(Expr))
 		}
 	case 63:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:364
+//line generated_parser.y:366
 		{
 			yyVAL.node = Expressions{yyDollar[1].node.(Expr)}
 		}
 	case 64:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:366
+//line generated_parser.y:368
 		{
 			yylex.(*parser).addParseErrf(yyDollar[2].item.PositionRange(), "trailing commas not allowed in function call args")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 65:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:377
+//line generated_parser.y:379
 		{
 			yyVAL.node = &ParenExpr{Expr: yyDollar[2].node.(Expr), PosRange: mergeRanges(&yyDollar[1].item, &yyDollar[3].item)}
 		}
 	case 66:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:385
+//line generated_parser.y:387
 		{
 			yylex.(*parser).addOffset(yyDollar[1].node, yyDollar[3].duration)
 			yyVAL.node = yyDollar[1].node
 		}
 	case 67:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:390
+//line generated_parser.y:392
 		{
 			yylex.(*parser).addOffset(yyDollar[1].node, -yyDollar[4].duration)
 			yyVAL.node = yyDollar[1].node
 		}
 	case 68:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:395
+//line generated_parser.y:397
 		{
 			yylex.(*parser).unexpected("offset", "duration")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 69:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:402
+//line generated_parser.y:404
 		{
 			yylex.(*parser).setTimestamp(yyDollar[1].node, yyDollar[3].float)
 			yyVAL.node = yyDollar[1].node
 		}
 	case 70:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line generated_parser.y:407
+//line generated_parser.y:409
 		{
 			yylex.(*parser).setAtModifierPreprocessor(yyDollar[1].node, yyDollar[3].item)
 			yyVAL.node = yyDollar[1].node
 		}
 	case 71:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:412
+//line generated_parser.y:414
 		{
 			yylex.(*parser).unexpected("@", "timestamp")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 74:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:422
+//line generated_parser.y:424
 		{
 			var errMsg string
 			vs, ok := yyDollar[1].node.(*VectorSelector)
@@ -1306,7 +1321,7 @@ yydefault:
 		}
 	case 75:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line generated_parser.y:447
+//line generated_parser.y:449
 		{
 			yyVAL.node = &SubqueryExpr{
 				Expr:  yyDollar[1].node.(Expr),
@@ -1318,35 +1333,35 @@ yydefault:
 		}
 	case 76:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line generated_parser.y:457
+//line generated_parser.y:459
 		{
 			yylex.(*parser).unexpected("subquery selector", "\"]\"")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 77:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line generated_parser.y:459
+//line generated_parser.y:461
 		{
 			yylex.(*parser).unexpected("subquery selector", "duration or \"]\"")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 78:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:461
+//line generated_parser.y:463
 		{
 			yylex.(*parser).unexpected("subquery or range", "\":\" or \"]\"")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 79:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:463
+//line generated_parser.y:465
 		{
 			yylex.(*parser).unexpected("subquery selector", "duration")
 			yyVAL.node = yyDollar[1].node
 		}
 	case 80:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:473
+//line generated_parser.y:475
 		{
 			if nl, ok := yyDollar[2].node.(*NumberLiteral); ok {
 				if yyDollar[1].item.Typ == SUB {
@@ -1360,7 +1375,7 @@ yydefault:
 		}
 	case 81:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:491
+//line generated_parser.y:493
 		{
 			vs := yyDollar[2].node.(*VectorSelector)
 			vs.PosRange = mergeRanges(&yyDollar[1].item, vs)
@@ -1370,7 +1385,7 @@ yydefault:
 		}
 	case 82:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:499
+//line generated_parser.y:501
 		{
 			vs := &VectorSelector{
 				Name:          yyDollar[1].item.Val,
@@ -1382,7 +1397,7 @@ yydefault:
 		}
 	case 83:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:509
+//line generated_parser.y:511
 		{
 			vs := yyDollar[1].node.(*VectorSelector)
 			yylex.(*parser).assembleVectorSelector(vs)
@@ -1390,7 +1405,7 @@ yydefault:
 		}
 	case 84:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:517
+//line generated_parser.y:519
 		{
 			yyVAL.node = &VectorSelector{
 				LabelMatchers: yyDollar[2].matchers,
@@ -1399,7 +1414,7 @@ yydefault:
 		}
 	case 85:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:524
+//line generated_parser.y:526
 		{
 			yyVAL.node = &VectorSelector{
 				LabelMatchers: yyDollar[2].matchers,
@@ -1408,7 +1423,7 @@ yydefault:
 		}
 	case 86:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:531
+//line generated_parser.y:533
 		{
 			yyVAL.node = &VectorSelector{
 				LabelMatchers: []*labels.Matcher{},
@@ -1417,7 +1432,7 @@ yydefault:
 		}
 	case 87:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:540
+//line generated_parser.y:542
 		{
 			if yyDollar[1].matchers != nil {
 				yyVAL.matchers = append(yyDollar[1].matchers, yyDollar[3].matcher)
@@ -1427,130 +1442,130 @@ yydefault:
 		}
 	case 88:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:548
+//line generated_parser.y:550
 		{
 			yyVAL.matchers = []*labels.Matcher{yyDollar[1].matcher}
 		}
 	case 89:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:550
+//line generated_parser.y:552
 		{
 			yylex.(*parser).unexpected("label matching", "\",\" or \"}\"")
 			yyVAL.matchers = yyDollar[1].matchers
 		}
 	case 90:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:554
+//line generated_parser.y:556
 		{
 			yyVAL.matcher = yylex.(*parser).newLabelMatcher(yyDollar[1].item, yyDollar[2].item, yyDollar[3].item)
 		}
 	case 91:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:556
+//line generated_parser.y:558
 		{
 			yylex.(*parser).unexpected("label matching", "string")
 			yyVAL.matcher = nil
 		}
 	case 92:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:558
+//line generated_parser.y:560
 		{
 			yylex.(*parser).unexpected("label matching", "label matching operator")
 			yyVAL.matcher = nil
 		}
 	case 93:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:560
+//line generated_parser.y:562
 		{
 			yylex.(*parser).unexpected("label matching", "identifier or \"}\"")
 			yyVAL.matcher = nil
 		}
 	case 94:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:568
+//line generated_parser.y:570
 		{
 			yyVAL.labels = append(yyDollar[2].labels, labels.Label{Name: labels.MetricName, Value: yyDollar[1].item.Val})
 			sort.Sort(yyVAL.labels)
 		}
 	case 95:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:570
+//line generated_parser.y:572
 		{
 			yyVAL.labels = yyDollar[1].labels
 		}
 	case 116:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:577
+//line generated_parser.y:579
 		{
 			yyVAL.labels = labels.New(yyDollar[2].labels...)
 		}
 	case 117:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:579
+//line generated_parser.y:581
 		{
 			yyVAL.labels = labels.New(yyDollar[2].labels...)
 		}
 	case 118:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:581
+//line generated_parser.y:583
 		{
 			yyVAL.labels = labels.New()
 		}
 	case 119:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line generated_parser.y:583
+//line generated_parser.y:585
 		{
 			yyVAL.labels = labels.New()
 		}
 	case 120:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:587
+//line generated_parser.y:589
 		{
 			yyVAL.labels = append(yyDollar[1].labels, yyDollar[3].label)
 		}
 	case 121:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:589
+//line generated_parser.y:591
 		{
 			yyVAL.labels = []labels.Label{yyDollar[1].label}
 		}
 	case 122:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:591
+//line generated_parser.y:593
 		{
 			yylex.(*parser).unexpected("label set", "\",\" or \"}\"")
 			yyVAL.labels = yyDollar[1].labels
 		}
 	case 123:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:596
+//line generated_parser.y:598
 		{
 			yyVAL.label = labels.Label{Name: yyDollar[1].item.Val, Value: yylex.(*parser).unquoteString(yyDollar[3].item.Val)}
 		}
 	case 124:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:598
+//line generated_parser.y:600
 		{
 			yylex.(*parser).unexpected("label set", "string")
 			yyVAL.label = labels.Label{}
 		}
 	case 125:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:600
+//line generated_parser.y:602
 		{
 			yylex.(*parser).unexpected("label set", "\"=\"")
 			yyVAL.label = labels.Label{}
 		}
 	case 126:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:602
+//line generated_parser.y:604
 		{
 			yylex.(*parser).unexpected("label set", "identifier or \"}\"")
 			yyVAL.label = labels.Label{}
 		}
 	case 127:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:610
+//line generated_parser.y:612
 		{
 			yylex.(*parser).generatedParserResult = &seriesDescription{
 				labels: yyDollar[1].labels,
@@ -1559,38 +1574,38 @@ yydefault:
 		}
 	case 128:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line generated_parser.y:619
+//line generated_parser.y:621
 		{
 			yyVAL.series = []SequenceValue{}
 		}
 	case 129:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:621
+//line generated_parser.y:623
 		{
 			yyVAL.series = append(yyDollar[1].series, yyDollar[3].series...)
 		}
 	case 130:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:623
+//line generated_parser.y:625
 		{
 			yyVAL.series = yyDollar[1].series
 		}
 	case 131:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:625
+//line generated_parser.y:627
 		{
 			yylex.(*parser).unexpected("series values", "")
 			yyVAL.series = nil
 		}
 	case 132:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:629
+//line generated_parser.y:631
 		{
 			yyVAL.series = []SequenceValue{{Omitted: true}}
 		}
 	case 133:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:631
+//line generated_parser.y:633
 		{
 			yyVAL.series = []SequenceValue{}
 			for i := uint64(0); i < yyDollar[3].uint; i++ {
@@ -1599,13 +1614,13 @@ yydefault:
 		}
 	case 134:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:638
+//line generated_parser.y:640
 		{
 			yyVAL.series = []SequenceValue{{Value: yyDollar[1].float}}
 		}
 	case 135:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line generated_parser.y:640
+//line generated_parser.y:642
 		{
 			yyVAL.series = []SequenceValue{}
 			for i := uint64(0); i <= yyDollar[3].uint; i++ {
@@ -1614,7 +1629,7 @@ yydefault:
 		}
 	case 136:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line generated_parser.y:647
+//line generated_parser.y:649
 		{
 			yyVAL.series = []SequenceValue{}
 			for i := uint64(0); i <= yyDollar[4].uint; i++ {
@@ -1624,43 +1639,43 @@ yydefault:
 		}
 	case 137:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:657
+//line generated_parser.y:659
 		{
 			if yyDollar[1].item.Val != "stale" {
 				yylex.(*parser).unexpected("series values", "number or \"stale\"")
 			}
 			yyVAL.float = math.Float64frombits(value.StaleNaN)
 		}
-	case 182:
+	case 184:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:688
+//line generated_parser.y:690
 		{
 			yyVAL.node = &NumberLiteral{
 				Val:      yylex.(*parser).number(yyDollar[1].item.Val),
 				PosRange: yyDollar[1].item.PositionRange(),
 			}
 		}
-	case 183:
+	case 185:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:696
+//line generated_parser.y:698
 		{
 			yyVAL.float = yylex.(*parser).number(yyDollar[1].item.Val)
 		}
-	case 184:
+	case 186:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:698
+//line generated_parser.y:700
 		{
 			yyVAL.float = yyDollar[2].float
 		}
-	case 185:
+	case 187:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line generated_parser.y:699
+//line generated_parser.y:701
 		{
 			yyVAL.float = -yyDollar[2].float
 		}
-	case 188:
+	case 190:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:705
+//line generated_parser.y:707
 		{
 			var err error
 			yyVAL.uint, err = strconv.ParseUint(yyDollar[1].item.Val, 10, 64)
@@ -1668,9 +1683,9 @@ yydefault:
 				yylex.(*parser).addParseErrf(yyDollar[1].item.PositionRange(), "invalid repetition in series values: %s", err)
 			}
 		}
-	case 189:
+	case 191:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:715
+//line generated_parser.y:717
 		{
 			var err error
 			yyVAL.duration, err = parseDuration(yyDollar[1].item.Val)
@@ -1678,24 +1693,24 @@ yydefault:
 				yylex.(*parser).addParseErr(yyDollar[1].item.PositionRange(), err)
 			}
 		}
-	case 190:
+	case 192:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line generated_parser.y:726
+//line generated_parser.y:728
 		{
 			yyVAL.node = &StringLiteral{
 				Val:      yylex.(*parser).unquoteString(yyDollar[1].item.Val),
 				PosRange: yyDollar[1].item.PositionRange(),
 			}
 		}
-	case 191:
+	case 193:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line generated_parser.y:739
+//line generated_parser.y:741
 		{
 			yyVAL.duration = 0
 		}
-	case 193:
+	case 195:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line generated_parser.y:743
+//line generated_parser.y:745
 		{
 			yyVAL.strings = nil
 		}
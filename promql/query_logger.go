@@ -18,7 +18,10 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -32,11 +35,74 @@ type ActiveQueryTracker struct {
 	getNextIndex  chan int
 	logger        log.Logger
 	maxConcurrent int
+	active        *activeQueryRegistry
+}
+
+// ActiveQuery describes a query that is currently being executed.
+type ActiveQuery struct {
+	QueryID         string    `json:"queryID,omitempty"`
+	Query           string    `json:"query"`
+	Start           time.Time `json:"start"`
+	SamplesLoaded   int64     `json:"samplesLoaded"`
+	PeakMemoryBytes int64     `json:"peakMemoryBytes"`
+}
+
+// bytesPerSample is the in-memory size of one promql.Point, used to turn the
+// peak sample count into a memory estimate.
+const bytesPerSample = 16
+
+// activeQueryEntry holds the live counters for one running query. The sample
+// counters are updated by the evaluator without additional locking.
+type activeQueryEntry struct {
+	samples     int64 // Accessed atomically, must be first for alignment.
+	peakSamples int64 // Accessed atomically.
+
+	queryID string
+	query   string
+	start   time.Time
+}
+
+// observeSamples records the current number of samples held in memory by the
+// query and raises the peak if needed.
+func (e *activeQueryEntry) observeSamples(n int64) {
+	atomic.StoreInt64(&e.samples, n)
+	for {
+		peak := atomic.LoadInt64(&e.peakSamples)
+		if n <= peak || atomic.CompareAndSwapInt64(&e.peakSamples, peak, n) {
+			return
+		}
+	}
+}
+
+// activeQueryRegistry is the in-memory counterpart of the mmaped active query
+// log, keyed by the file offset of the entry.
+type activeQueryRegistry struct {
+	mtx     sync.Mutex
+	entries map[int]*activeQueryEntry
+}
+
+func newActiveQueryRegistry() *activeQueryRegistry {
+	return &activeQueryRegistry{entries: map[int]*activeQueryEntry{}}
+}
+
+func (r *activeQueryRegistry) insert(insertIndex int, query, queryID string) *activeQueryEntry {
+	e := &activeQueryEntry{queryID: queryID, query: query, start: time.Now()}
+	r.mtx.Lock()
+	r.entries[insertIndex] = e
+	r.mtx.Unlock()
+	return e
+}
+
+func (r *activeQueryRegistry) delete(insertIndex int) {
+	r.mtx.Lock()
+	delete(r.entries, insertIndex)
+	r.mtx.Unlock()
 }
 
 type Entry struct {
 	Query     string `json:"query"`
 	Timestamp int64  `json:"timestamp_sec"`
+	QueryID   string `json:"query_id,omitempty"`
 }
 
 const (
@@ -123,6 +189,7 @@ func NewActiveQueryTracker(localStoragePath string, maxConcurrent int, logger lo
 		getNextIndex:  make(chan int, maxConcurrent),
 		logger:        logger,
 		maxConcurrent: maxConcurrent,
+		active:        newActiveQueryRegistry(),
 	}
 
 	activeQueryTracker.generateIndices(maxConcurrent)
@@ -144,8 +211,8 @@ func trimStringByBytes(str string, size int) string {
 	return string(bytesStr[:trimIndex])
 }
 
-func _newJSONEntry(query string, timestamp int64, logger log.Logger) []byte {
-	entry := Entry{query, timestamp}
+func _newJSONEntry(query, queryID string, timestamp int64, logger log.Logger) []byte {
+	entry := Entry{Query: query, Timestamp: timestamp, QueryID: queryID}
 	jsonEntry, err := json.Marshal(entry)
 
 	if err != nil {
@@ -156,12 +223,12 @@ func _newJSONEntry(query string, timestamp int64, logger log.Logger) []byte {
 	return jsonEntry
 }
 
-func newJSONEntry(query string, logger log.Logger) []byte {
+func newJSONEntry(query, queryID string, logger log.Logger) []byte {
 	timestamp := time.Now().Unix()
-	minEntryJSON := _newJSONEntry("", timestamp, logger)
+	minEntryJSON := _newJSONEntry("", queryID, timestamp, logger)
 
 	query = trimStringByBytes(query, entrySize-(len(minEntryJSON)+1))
-	jsonEntry := _newJSONEntry(query, timestamp, logger)
+	jsonEntry := _newJSONEntry(query, queryID, timestamp, logger)
 
 	return jsonEntry
 }
@@ -177,6 +244,7 @@ func (tracker ActiveQueryTracker) GetMaxConcurrent() int {
 }
 
 func (tracker ActiveQueryTracker) Delete(insertIndex int) {
+	tracker.active.delete(insertIndex)
 	copy(tracker.mmapedFile[insertIndex:], strings.Repeat("\x00", entrySize))
 	tracker.getNextIndex <- insertIndex
 }
@@ -185,13 +253,42 @@ func (tracker ActiveQueryTracker) Insert(ctx context.Context, query string) (int
 	select {
 	case i := <-tracker.getNextIndex:
 		fileBytes := tracker.mmapedFile
-		entry := newJSONEntry(query, tracker.logger)
+		entry := newJSONEntry(query, QueryIDFromContext(ctx), tracker.logger)
 		start, end := i, i+entrySize
 
 		copy(fileBytes[start:], entry)
 		copy(fileBytes[end-1:], ",")
+		tracker.active.insert(i, query, QueryIDFromContext(ctx))
 		return i, nil
 	case <-ctx.Done():
 		return 0, ctx.Err()
 	}
 }
+
+// entry returns the live counters for the query at the given insert index, or
+// nil if it is no longer tracked.
+func (tracker ActiveQueryTracker) entry(insertIndex int) *activeQueryEntry {
+	tracker.active.mtx.Lock()
+	defer tracker.active.mtx.Unlock()
+	return tracker.active.entries[insertIndex]
+}
+
+// ActiveQueries returns a snapshot of all queries currently being executed,
+// ordered by their start time.
+func (tracker ActiveQueryTracker) ActiveQueries() []ActiveQuery {
+	tracker.active.mtx.Lock()
+	queries := make([]ActiveQuery, 0, len(tracker.active.entries))
+	for _, e := range tracker.active.entries {
+		queries = append(queries, ActiveQuery{
+			QueryID:         e.queryID,
+			Query:           e.query,
+			Start:           e.start,
+			SamplesLoaded:   atomic.LoadInt64(&e.samples),
+			PeakMemoryBytes: atomic.LoadInt64(&e.peakSamples) * bytesPerSample,
+		})
+	}
+	tracker.active.mtx.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Start.Before(queries[j].Start) })
+	return queries
+}
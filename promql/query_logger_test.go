@@ -29,6 +29,7 @@ func TestQueryLogging(t *testing.T) {
 		mmapedFile:   fileAsBytes,
 		logger:       nil,
 		getNextIndex: make(chan int, 4),
+		active:       newActiveQueryRegistry(),
 	}
 
 	queryLogger.generateIndices(4)
@@ -75,6 +76,7 @@ func TestIndexReuse(t *testing.T) {
 		mmapedFile:   queryBytes,
 		logger:       nil,
 		getNextIndex: make(chan int, 3),
+		active:       newActiveQueryRegistry(),
 	}
 
 	queryLogger.generateIndices(3)
@@ -107,6 +109,32 @@ func TestIndexReuse(t *testing.T) {
 	}
 }
 
+func TestActiveQueries(t *testing.T) {
+	queryLogger := ActiveQueryTracker{
+		mmapedFile:   make([]byte, 4096),
+		logger:       nil,
+		getNextIndex: make(chan int, 4),
+		active:       newActiveQueryRegistry(),
+	}
+	queryLogger.generateIndices(4)
+
+	i, err := queryLogger.Insert(context.Background(), "TestQuery")
+	require.NoError(t, err)
+
+	queryLogger.entry(i).observeSamples(100)
+	queryLogger.entry(i).observeSamples(40)
+
+	queries := queryLogger.ActiveQueries()
+	require.Equal(t, 1, len(queries))
+	require.Equal(t, "TestQuery", queries[0].Query)
+	require.Equal(t, int64(40), queries[0].SamplesLoaded)
+	require.Equal(t, int64(100*bytesPerSample), queries[0].PeakMemoryBytes)
+	require.False(t, queries[0].Start.IsZero())
+
+	queryLogger.Delete(i)
+	require.Equal(t, 0, len(queryLogger.ActiveQueries()))
+}
+
 func TestMMapFile(t *testing.T) {
 	file, err := ioutil.TempFile("", "mmapedFile")
 	require.NoError(t, err)
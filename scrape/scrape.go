@@ -170,6 +170,12 @@ var (
 			Help: "Total number of exemplar rejected due to not being out of the expected order.",
 		},
 	)
+	targetScrapeInvalidLines = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "prometheus_target_scrapes_skipped_invalid_lines_total",
+			Help: "Total number of invalid lines skipped during tolerant parsing.",
+		},
+	)
 )
 
 func init() {
@@ -192,6 +198,7 @@ func init() {
 		targetScrapeCacheFlushForced,
 		targetMetadataCache,
 		targetScrapeExemplarOutOfOrder,
+		targetScrapeInvalidLines,
 	)
 }
 
@@ -224,6 +231,7 @@ type scrapeLoopOptions struct {
 	limit           int
 	honorLabels     bool
 	honorTimestamps bool
+	tolerantParsing bool
 	mrc             []*relabel.Config
 	cache           *scrapeCache
 }
@@ -277,6 +285,7 @@ func newScrapePool(cfg *config.ScrapeConfig, app storage.Appendable, jitterSeed
 			cache,
 			jitterSeed,
 			opts.honorTimestamps,
+			opts.tolerantParsing,
 		)
 	}
 
@@ -363,6 +372,7 @@ func (sp *scrapePool) reload(cfg *config.ScrapeConfig) error {
 		limit           = int(sp.config.SampleLimit)
 		honorLabels     = sp.config.HonorLabels
 		honorTimestamps = sp.config.HonorTimestamps
+		tolerantParsing = sp.config.TolerantParsing
 		mrc             = sp.config.MetricRelabelConfigs
 	)
 
@@ -386,6 +396,7 @@ func (sp *scrapePool) reload(cfg *config.ScrapeConfig) error {
 				limit:           limit,
 				honorLabels:     honorLabels,
 				honorTimestamps: honorTimestamps,
+				tolerantParsing: tolerantParsing,
 				mrc:             mrc,
 				cache:           cache,
 			})
@@ -457,6 +468,7 @@ func (sp *scrapePool) sync(targets []*Target) {
 		limit           = int(sp.config.SampleLimit)
 		honorLabels     = sp.config.HonorLabels
 		honorTimestamps = sp.config.HonorTimestamps
+		tolerantParsing = sp.config.TolerantParsing
 		mrc             = sp.config.MetricRelabelConfigs
 	)
 
@@ -472,6 +484,7 @@ func (sp *scrapePool) sync(targets []*Target) {
 				limit:           limit,
 				honorLabels:     honorLabels,
 				honorTimestamps: honorTimestamps,
+				tolerantParsing: tolerantParsing,
 				mrc:             mrc,
 			})
 
@@ -694,7 +707,7 @@ type cacheEntry struct {
 	ref      uint64
 	lastIter uint64
 	hash     uint64
-	lset     labels.Labels
+	lset     labels.Interned
 }
 
 type scrapeLoop struct {
@@ -705,6 +718,7 @@ type scrapeLoop struct {
 	buffers         *pool.Pool
 	jitterSeed      uint64
 	honorTimestamps bool
+	tolerantParsing bool
 	forcedErr       error
 	forcedErrMtx    sync.Mutex
 
@@ -741,8 +755,8 @@ type scrapeCache struct {
 	// seriesCur and seriesPrev store the labels of series that were seen
 	// in the current and previous scrape.
 	// We hold two maps and swap them out to save allocations.
-	seriesCur  map[uint64]labels.Labels
-	seriesPrev map[uint64]labels.Labels
+	seriesCur  map[uint64]labels.Interned
+	seriesPrev map[uint64]labels.Interned
 
 	metaMtx  sync.Mutex
 	metadata map[string]*metaEntry
@@ -765,8 +779,8 @@ func newScrapeCache() *scrapeCache {
 	return &scrapeCache{
 		series:        map[string]*cacheEntry{},
 		droppedSeries: map[string]*uint64{},
-		seriesCur:     map[uint64]labels.Labels{},
-		seriesPrev:    map[uint64]labels.Labels{},
+		seriesCur:     map[uint64]labels.Interned{},
+		seriesPrev:    map[uint64]labels.Interned{},
 		metadata:      map[string]*metaEntry{},
 	}
 }
@@ -832,7 +846,7 @@ func (c *scrapeCache) get(met string) (*cacheEntry, bool) {
 	return e, true
 }
 
-func (c *scrapeCache) addRef(met string, ref uint64, lset labels.Labels, hash uint64) {
+func (c *scrapeCache) addRef(met string, ref uint64, lset labels.Interned, hash uint64) {
 	if ref == 0 {
 		return
 	}
@@ -852,14 +866,14 @@ func (c *scrapeCache) getDropped(met string) bool {
 	return ok
 }
 
-func (c *scrapeCache) trackStaleness(hash uint64, lset labels.Labels) {
+func (c *scrapeCache) trackStaleness(hash uint64, lset labels.Interned) {
 	c.seriesCur[hash] = lset
 }
 
 func (c *scrapeCache) forEachStale(f func(labels.Labels) bool) {
 	for h, lset := range c.seriesPrev {
 		if _, ok := c.seriesCur[h]; !ok {
-			if !f(lset) {
+			if !f(lset.Labels()) {
 				break
 			}
 		}
@@ -974,6 +988,7 @@ func newScrapeLoop(ctx context.Context,
 	cache *scrapeCache,
 	jitterSeed uint64,
 	honorTimestamps bool,
+	tolerantParsing bool,
 ) *scrapeLoop {
 	if l == nil {
 		l = log.NewNopLogger()
@@ -996,6 +1011,7 @@ func newScrapeLoop(ctx context.Context,
 		l:                   l,
 		parentCtx:           ctx,
 		honorTimestamps:     honorTimestamps,
+		tolerantParsing:     tolerantParsing,
 	}
 	sl.ctx, sl.cancel = context.WithCancel(ctx)
 
@@ -1261,12 +1277,18 @@ type appendErrors struct {
 
 func (sl *scrapeLoop) append(app storage.Appender, b []byte, contentType string, ts time.Time) (total, added, seriesAdded int, err error) {
 	var (
-		p              = textparse.New(b, contentType)
-		defTime        = timestamp.FromTime(ts)
-		appErrs        = appendErrors{}
+		p              textparse.Parser = textparse.New(b, contentType)
+		defTime                         = timestamp.FromTime(ts)
+		appErrs                         = appendErrors{}
 		sampleLimitErr error
 	)
 
+	var tolerantP *textparse.TolerantParser
+	if sl.tolerantParsing {
+		tolerantP = textparse.NewTolerant(b, contentType)
+		p = tolerantP
+	}
+
 	defer func() {
 		if err != nil {
 			return
@@ -1327,7 +1349,7 @@ loop:
 
 		if ok {
 			ref = ce.ref
-			lset = ce.lset
+			lset = ce.lset.Labels()
 		} else {
 			mets = p.Metric(&lset)
 			hash = lset.Hash()
@@ -1358,11 +1380,12 @@ loop:
 		}
 
 		if !ok {
+			ilset := labels.Intern(lset)
 			if tp == nil {
 				// Bypass staleness logic if there is an explicit timestamp.
-				sl.cache.trackStaleness(hash, lset)
+				sl.cache.trackStaleness(hash, ilset)
 			}
-			sl.cache.addRef(mets, ref, lset, hash)
+			sl.cache.addRef(mets, ref, ilset, hash)
 			if sampleAdded && sampleLimitErr == nil {
 				seriesAdded++
 			}
@@ -1404,6 +1427,12 @@ loop:
 	if appErrs.numExemplarOutOfOrder > 0 {
 		level.Warn(sl.l).Log("msg", "Error on ingesting out-of-order exemplars", "num_dropped", appErrs.numExemplarOutOfOrder)
 	}
+	if tolerantP != nil {
+		if errs := tolerantP.Errors(); len(errs) > 0 {
+			targetScrapeInvalidLines.Add(float64(len(errs)))
+			level.Warn(sl.l).Log("msg", "Skipped invalid lines during scrape", "num_skipped", len(errs), "first_error", errs[0])
+		}
+	}
 	if err == nil {
 		sl.cache.forEachStale(func(lset labels.Labels) bool {
 			// Series no longer exposed, mark it stale.
@@ -1542,7 +1571,7 @@ func (sl *scrapeLoop) addReportSample(app storage.Appender, s string, t int64, v
 	var lset labels.Labels
 	if ok {
 		ref = ce.ref
-		lset = ce.lset
+		lset = ce.lset.Labels()
 	} else {
 		lset = labels.Labels{
 			// The constants are suffixed with the invalid \xff unicode rune to avoid collisions
@@ -1557,7 +1586,7 @@ func (sl *scrapeLoop) addReportSample(app storage.Appender, s string, t int64, v
 	switch errors.Cause(err) {
 	case nil:
 		if !ok {
-			sl.cache.addRef(s, ref, lset, lset.Hash())
+			sl.cache.addRef(s, ref, labels.Intern(lset), lset.Hash())
 		}
 		return nil
 	case storage.ErrOutOfOrderSample, storage.ErrDuplicateSampleForTimestamp:
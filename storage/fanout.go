@@ -15,9 +15,13 @@ package storage
 
 import (
 	"context"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 
 	"github.com/adolli/prometheus/pkg/exemplar"
@@ -25,11 +29,38 @@ import (
 	tsdb_errors "github.com/adolli/prometheus/tsdb/errors"
 )
 
+// SecondaryErrorPolicy controls how a fanout storage reacts to errors from
+// its secondary appenders.
+type SecondaryErrorPolicy string
+
+const (
+	// FailCommit propagates secondary appender errors to the caller, failing
+	// the whole append batch. This is the historical behavior and the default.
+	FailCommit SecondaryErrorPolicy = "fail-commit"
+	// LogAndContinue logs secondary appender errors and carries on; the
+	// primary storage is unaffected.
+	LogAndContinue SecondaryErrorPolicy = "log-and-continue"
+	// CircuitBreak behaves like LogAndContinue but additionally stops
+	// appending to a secondary after repeated consecutive failures, retrying
+	// it after a cooldown period.
+	CircuitBreak SecondaryErrorPolicy = "circuit-break"
+)
+
+const (
+	// Number of consecutive secondary appender errors after which the
+	// secondary's circuit breaker opens.
+	secondaryBreakerThreshold = 5
+	// How long an open circuit breaker skips a secondary before retrying it.
+	secondaryBreakerCooldown = 1 * time.Minute
+)
+
 type fanout struct {
 	logger log.Logger
 
-	primary     Storage
-	secondaries []Storage
+	primary         Storage
+	secondaries     []Storage
+	secondaryPolicy SecondaryErrorPolicy
+	secondaryStates []*secondaryState
 }
 
 // NewFanout returns a new fanout Storage, which proxies reads and writes
@@ -42,11 +73,115 @@ type fanout struct {
 //
 // NOTE: In the case of Prometheus, it treats all remote storages as secondary / best effort.
 func NewFanout(logger log.Logger, primary Storage, secondaries ...Storage) Storage {
+	return NewFanoutWithOptions(logger, nil, FailCommit, primary, secondaries...)
+}
+
+// NewFanoutWithOptions is like NewFanout but additionally configures how
+// errors from secondary appenders are handled and registers per-secondary
+// health metrics with reg.
+func NewFanoutWithOptions(logger log.Logger, reg prometheus.Registerer, policy SecondaryErrorPolicy, primary Storage, secondaries ...Storage) Storage {
+	errs := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_fanout_storage_secondary_errors_total",
+		Help: "Total number of errors returned by secondary storage appenders.",
+	}, []string{"secondary"})
+	healthy := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "prometheus_fanout_storage_secondary_healthy",
+		Help: "Whether appends to the secondary storage are attempted (1) or its circuit breaker is open (0).",
+	}, []string{"secondary"})
+	if reg != nil {
+		reg.MustRegister(errs, healthy)
+	}
+
+	states := make([]*secondaryState, 0, len(secondaries))
+	for i := range secondaries {
+		s := &secondaryState{
+			errs:    errs.WithLabelValues(strconv.Itoa(i)),
+			healthy: healthy.WithLabelValues(strconv.Itoa(i)),
+		}
+		s.healthy.Set(1)
+		states = append(states, s)
+	}
 	return &fanout{
-		logger:      logger,
-		primary:     primary,
-		secondaries: secondaries,
+		logger:          logger,
+		primary:         primary,
+		secondaries:     secondaries,
+		secondaryPolicy: policy,
+		secondaryStates: states,
+	}
+}
+
+// secondaryState tracks the health of a single secondary storage across all
+// appenders of a fanout. It implements a simple circuit breaker that opens
+// after secondaryBreakerThreshold consecutive failed append batches.
+type secondaryState struct {
+	mtx       sync.Mutex
+	failures  int
+	openUntil time.Time
+
+	errs    prometheus.Counter
+	healthy prometheus.Gauge
+}
+
+// allow returns whether the secondary should be appended to.
+func (s *secondaryState) allow(now time.Time) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.openUntil.IsZero() || !now.Before(s.openUntil)
+}
+
+// recordError counts an error and, when the circuit breaker is active,
+// opens it after too many consecutive failures.
+func (s *secondaryState) recordError(breaker bool, now time.Time) {
+	s.errs.Inc()
+	if !breaker {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.failures++
+	if s.failures >= secondaryBreakerThreshold {
+		s.openUntil = now.Add(secondaryBreakerCooldown)
+		s.healthy.Set(0)
+	}
+}
+
+// recordSuccess closes the circuit breaker after a successful commit.
+func (s *secondaryState) recordSuccess() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.failures = 0
+	s.openUntil = time.Time{}
+	s.healthy.Set(1)
+}
+
+// ReadPreference restricts which storages of a fanout serve a read. It is
+// attached to the query context and defaults to reading from all storages.
+type ReadPreference string
+
+const (
+	// ReadAll reads from the primary and all secondary storages.
+	ReadAll ReadPreference = "all"
+	// ReadLocal reads from the primary (local) storage only.
+	ReadLocal ReadPreference = "local"
+	// ReadRemote reads from the secondary (remote) storages only.
+	ReadRemote ReadPreference = "remote"
+)
+
+type readPreferenceKey struct{}
+
+// ContextWithReadPreference returns a new context carrying the given read
+// preference for fanout storages.
+func ContextWithReadPreference(ctx context.Context, pref ReadPreference) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, pref)
+}
+
+// ReadPreferenceFromContext returns the read preference attached to the
+// context, defaulting to ReadAll.
+func ReadPreferenceFromContext(ctx context.Context) ReadPreference {
+	if pref, ok := ctx.Value(readPreferenceKey{}).(ReadPreference); ok {
+		return pref
 	}
+	return ReadAll
 }
 
 // StartTime implements the Storage interface.
@@ -71,9 +206,18 @@ func (f *fanout) StartTime() (int64, error) {
 }
 
 func (f *fanout) Querier(ctx context.Context, mint, maxt int64) (Querier, error) {
-	primary, err := f.primary.Querier(ctx, mint, maxt)
-	if err != nil {
-		return nil, err
+	pref := ReadPreferenceFromContext(ctx)
+
+	var primary Querier = NoopQuerier()
+	if pref != ReadRemote {
+		var err error
+		primary, err = f.primary.Querier(ctx, mint, maxt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pref == ReadLocal {
+		return primary, nil
 	}
 
 	secondaries := make([]Querier, 0, len(f.secondaries))
@@ -93,9 +237,18 @@ func (f *fanout) Querier(ctx context.Context, mint, maxt int64) (Querier, error)
 }
 
 func (f *fanout) ChunkQuerier(ctx context.Context, mint, maxt int64) (ChunkQuerier, error) {
-	primary, err := f.primary.ChunkQuerier(ctx, mint, maxt)
-	if err != nil {
-		return nil, err
+	pref := ReadPreferenceFromContext(ctx)
+
+	var primary ChunkQuerier = NoopChunkedQuerier()
+	if pref != ReadRemote {
+		var err error
+		primary, err = f.primary.ChunkQuerier(ctx, mint, maxt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pref == ReadLocal {
+		return primary, nil
 	}
 
 	secondaries := make([]ChunkQuerier, 0, len(f.secondaries))
@@ -117,13 +270,23 @@ func (f *fanout) ChunkQuerier(ctx context.Context, mint, maxt int64) (ChunkQueri
 func (f *fanout) Appender(ctx context.Context) Appender {
 	primary := f.primary.Appender(ctx)
 	secondaries := make([]Appender, 0, len(f.secondaries))
-	for _, storage := range f.secondaries {
+	states := make([]*secondaryState, 0, len(f.secondaries))
+	for i, storage := range f.secondaries {
+		state := f.secondaryStates[i]
+		// Skip secondaries whose circuit breaker is open.
+		if f.secondaryPolicy == CircuitBreak && !state.allow(time.Now()) {
+			continue
+		}
 		secondaries = append(secondaries, storage.Appender(ctx))
+		states = append(states, state)
 	}
 	return &fanoutAppender{
 		logger:      f.logger,
 		primary:     primary,
 		secondaries: secondaries,
+		policy:      f.secondaryPolicy,
+		states:      states,
+		failed:      make([]bool, len(states)),
 	}
 }
 
@@ -142,6 +305,21 @@ type fanoutAppender struct {
 
 	primary     Appender
 	secondaries []Appender
+	policy      SecondaryErrorPolicy
+	states      []*secondaryState // Aligned with secondaries.
+	failed      []bool            // Whether the secondary returned an error during this batch.
+}
+
+// handleSecondaryError records an error of the i-th secondary appender and
+// returns whether it should be propagated to the caller.
+func (f *fanoutAppender) handleSecondaryError(i int, op string, err error) bool {
+	f.states[i].recordError(f.policy == CircuitBreak, time.Now())
+	f.failed[i] = true
+	if f.policy == FailCommit {
+		return true
+	}
+	level.Warn(f.logger).Log("msg", "Error in secondary storage appender", "op", op, "secondary", i, "err", err)
+	return false
 }
 
 func (f *fanoutAppender) Append(ref uint64, l labels.Labels, t int64, v float64) (uint64, error) {
@@ -150,9 +328,11 @@ func (f *fanoutAppender) Append(ref uint64, l labels.Labels, t int64, v float64)
 		return ref, err
 	}
 
-	for _, appender := range f.secondaries {
+	for i, appender := range f.secondaries {
 		if _, err := appender.Append(ref, l, t, v); err != nil {
-			return 0, err
+			if f.handleSecondaryError(i, "append", err) {
+				return 0, err
+			}
 		}
 	}
 	return ref, nil
@@ -164,9 +344,11 @@ func (f *fanoutAppender) AppendExemplar(ref uint64, l labels.Labels, e exemplar.
 		return ref, err
 	}
 
-	for _, appender := range f.secondaries {
+	for i, appender := range f.secondaries {
 		if _, err := appender.AppendExemplar(ref, l, e); err != nil {
-			return 0, err
+			if f.handleSecondaryError(i, "append_exemplar", err) {
+				return 0, err
+			}
 		}
 	}
 	return ref, nil
@@ -175,9 +357,15 @@ func (f *fanoutAppender) AppendExemplar(ref uint64, l labels.Labels, e exemplar.
 func (f *fanoutAppender) Commit() (err error) {
 	err = f.primary.Commit()
 
-	for _, appender := range f.secondaries {
+	for i, appender := range f.secondaries {
 		if err == nil {
-			err = appender.Commit()
+			if commitErr := appender.Commit(); commitErr != nil {
+				if f.handleSecondaryError(i, "commit", commitErr) {
+					err = commitErr
+				}
+			} else if !f.failed[i] {
+				f.states[i].recordSuccess()
+			}
 		} else {
 			if rollbackErr := appender.Rollback(); rollbackErr != nil {
 				level.Error(f.logger).Log("msg", "Squashed rollback error on commit", "err", rollbackErr)
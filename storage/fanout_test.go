@@ -17,15 +17,70 @@ import (
 	"context"
 	"testing"
 
+	"github.com/go-kit/kit/log"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/storage"
 	"github.com/adolli/prometheus/util/teststorage"
 )
 
+func TestFanout_ReadPreference(t *testing.T) {
+	inputLabel := labels.FromStrings(model.MetricNameLabel, "a")
+	ctx := context.Background()
+
+	priStorage := teststorage.New(t)
+	defer priStorage.Close()
+	app1 := priStorage.Appender(ctx)
+	app1.Append(0, inputLabel, 0, 0)
+	app1.Append(0, inputLabel, 1000, 1)
+	require.NoError(t, app1.Commit())
+
+	remoteStorage := teststorage.New(t)
+	defer remoteStorage.Close()
+	app2 := remoteStorage.Appender(ctx)
+	app2.Append(0, inputLabel, 2000, 2)
+	app2.Append(0, inputLabel, 3000, 3)
+	app2.Append(0, inputLabel, 4000, 4)
+	require.NoError(t, app2.Commit())
+
+	fanoutStorage := storage.NewFanout(nil, priStorage, remoteStorage)
+
+	matcher, err := labels.NewMatcher(labels.MatchEqual, model.MetricNameLabel, "a")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		pref       storage.ReadPreference
+		expSamples int
+	}{
+		{pref: storage.ReadAll, expSamples: 5},
+		{pref: storage.ReadLocal, expSamples: 2},
+		{pref: storage.ReadRemote, expSamples: 3},
+	} {
+		t.Run(string(tc.pref), func(t *testing.T) {
+			ctx := storage.ContextWithReadPreference(context.Background(), tc.pref)
+			querier, err := fanoutStorage.Querier(ctx, 0, 5000)
+			require.NoError(t, err)
+			defer querier.Close()
+
+			seriesSet := querier.Select(true, nil, matcher)
+			samples := 0
+			for seriesSet.Next() {
+				it := seriesSet.At().Iterator()
+				for it.Next() {
+					samples++
+				}
+				require.NoError(t, it.Err())
+			}
+			require.NoError(t, seriesSet.Err())
+			require.Equal(t, tc.expSamples, samples)
+		})
+	}
+}
+
 func TestFanout_SelectSorted(t *testing.T) {
 	inputLabel := labels.FromStrings(model.MetricNameLabel, "a")
 	outputLabel := labels.FromStrings(model.MetricNameLabel, "a")
@@ -207,6 +262,52 @@ func TestFanoutErrors(t *testing.T) {
 	}
 }
 
+func TestFanout_SecondaryErrorPolicy(t *testing.T) {
+	inputLabel := labels.FromStrings(model.MetricNameLabel, "a")
+	ctx := context.Background()
+
+	t.Run("fail-commit", func(t *testing.T) {
+		priStorage := teststorage.New(t)
+		defer priStorage.Close()
+		failing := &failingStorage{err: errors.New("secondary append error")}
+
+		fanoutStorage := storage.NewFanoutWithOptions(log.NewNopLogger(), nil, storage.FailCommit, priStorage, failing)
+		app := fanoutStorage.Appender(ctx)
+		_, err := app.Append(0, inputLabel, 0, 1)
+		require.Error(t, err)
+	})
+
+	t.Run("log-and-continue", func(t *testing.T) {
+		priStorage := teststorage.New(t)
+		defer priStorage.Close()
+		failing := &failingStorage{err: errors.New("secondary append error")}
+
+		fanoutStorage := storage.NewFanoutWithOptions(log.NewNopLogger(), nil, storage.LogAndContinue, priStorage, failing)
+		app := fanoutStorage.Appender(ctx)
+		_, err := app.Append(0, inputLabel, 0, 1)
+		require.NoError(t, err)
+		require.NoError(t, app.Commit())
+		require.Equal(t, 1, failing.appends)
+	})
+
+	t.Run("circuit-break", func(t *testing.T) {
+		priStorage := teststorage.New(t)
+		defer priStorage.Close()
+		failing := &failingStorage{err: errors.New("secondary append error")}
+
+		fanoutStorage := storage.NewFanoutWithOptions(log.NewNopLogger(), nil, storage.CircuitBreak, priStorage, failing)
+		// Each failed append counts towards the breaker; after enough
+		// consecutive failures new appenders skip the secondary.
+		for i := 0; i < 10; i++ {
+			app := fanoutStorage.Appender(ctx)
+			_, err := app.Append(0, inputLabel, int64(i*1000), 1)
+			require.NoError(t, err)
+			require.NoError(t, app.Commit())
+		}
+		require.Equal(t, 5, failing.appends)
+	})
+}
+
 var errSelect = errors.New("select error")
 
 type errStorage struct{}
@@ -243,3 +344,31 @@ func (errQuerier) Close() error { return nil }
 func (errChunkQuerier) Select(bool, *storage.SelectHints, ...*labels.Matcher) storage.ChunkSeriesSet {
 	return storage.ErrChunkSeriesSet(errSelect)
 }
+
+// failingStorage is a secondary storage whose appender fails every Append
+// but commits successfully.
+type failingStorage struct {
+	errStorage
+	appends int
+	err     error
+}
+
+func (s *failingStorage) Appender(_ context.Context) storage.Appender {
+	return failingAppender{s: s}
+}
+
+type failingAppender struct {
+	s *failingStorage
+}
+
+func (a failingAppender) Append(uint64, labels.Labels, int64, float64) (uint64, error) {
+	a.s.appends++
+	return 0, a.s.err
+}
+
+func (a failingAppender) AppendExemplar(uint64, labels.Labels, exemplar.Exemplar) (uint64, error) {
+	return 0, a.s.err
+}
+
+func (failingAppender) Commit() error   { return nil }
+func (failingAppender) Rollback() error { return nil }
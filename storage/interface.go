@@ -140,6 +140,12 @@ type SelectHints struct {
 	Grouping []string // List of label names used in aggregation.
 	By       bool     // Indicate whether it is without or by.
 	Range    int64    // Range vector selector range in milliseconds.
+
+	// Limit is an upper bound on the number of series the caller will
+	// consume, so implementations may stop early. 0 means unlimited.
+	// Callers must still enforce the limit themselves as implementations
+	// are free to ignore it.
+	Limit int
 }
 
 // TODO(bwplotka): Move to promql/engine_test.go?
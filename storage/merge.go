@@ -16,6 +16,7 @@ package storage
 import (
 	"bytes"
 	"container/heap"
+	"context"
 	"math"
 	"sort"
 	"strings"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/tsdb/chunkenc"
 	"github.com/adolli/prometheus/tsdb/chunks"
@@ -715,3 +717,43 @@ func (h *chunkIteratorHeap) Pop() interface{} {
 	*h = old[0 : n-1]
 	return x
 }
+
+// NewMergeExemplarQueryable returns an ExemplarQueryable that concatenates the
+// results of all given exemplar queryables.
+func NewMergeExemplarQueryable(queryables ...ExemplarQueryable) ExemplarQueryable {
+	return &mergeExemplarQueryable{queryables: queryables}
+}
+
+type mergeExemplarQueryable struct {
+	queryables []ExemplarQueryable
+}
+
+// ExemplarQuerier implements ExemplarQueryable.
+func (q *mergeExemplarQueryable) ExemplarQuerier(ctx context.Context) (ExemplarQuerier, error) {
+	queriers := make([]ExemplarQuerier, 0, len(q.queryables))
+	for _, queryable := range q.queryables {
+		querier, err := queryable.ExemplarQuerier(ctx)
+		if err != nil {
+			return nil, err
+		}
+		queriers = append(queriers, querier)
+	}
+	return &mergeExemplarQuerier{queriers: queriers}, nil
+}
+
+type mergeExemplarQuerier struct {
+	queriers []ExemplarQuerier
+}
+
+// Select implements ExemplarQuerier.
+func (q *mergeExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	var results []exemplar.QueryResult
+	for _, querier := range q.queriers {
+		res, err := querier.Select(start, end, matchers...)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
 
+	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/pkg/textparse"
 	"github.com/adolli/prometheus/prompb"
@@ -487,6 +488,15 @@ func labelsToLabelsProto(labels labels.Labels, buf []prompb.Label) []prompb.Labe
 	return result
 }
 
+// exemplarToExemplarProto transforms an exemplar into a prompb exemplar.
+func exemplarToExemplarProto(e exemplar.Exemplar) prompb.Exemplar {
+	return prompb.Exemplar{
+		Labels:    labelsToLabelsProto(e.Labels, nil),
+		Value:     e.Value,
+		Timestamp: e.Ts,
+	}
+}
+
 // metricTypeToMetricTypeProto transforms a Prometheus metricType into prompb metricType. Since the former is a string we need to transform it to an enum.
 func metricTypeToMetricTypeProto(t textparse.MetricType) prompb.MetricMetadata_MetricType {
 	mt := strings.ToUpper(string(t))
@@ -26,10 +26,9 @@ import (
 	"github.com/golang/snappy"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
 	"go.uber.org/atomic"
 
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/common/model"
 	"github.com/adolli/prometheus/config"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/pkg/relabel"
@@ -37,6 +36,8 @@ import (
 	"github.com/adolli/prometheus/scrape"
 	"github.com/adolli/prometheus/tsdb/record"
 	"github.com/adolli/prometheus/tsdb/wal"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 )
 
 const (
@@ -52,25 +53,26 @@ const (
 type queueManagerMetrics struct {
 	reg prometheus.Registerer
 
-	samplesTotal         prometheus.Counter
-	metadataTotal        prometheus.Counter
-	failedSamplesTotal   prometheus.Counter
-	failedMetadataTotal  prometheus.Counter
-	retriedSamplesTotal  prometheus.Counter
-	retriedMetadataTotal prometheus.Counter
-	droppedSamplesTotal  prometheus.Counter
-	enqueueRetriesTotal  prometheus.Counter
-	sentBatchDuration    prometheus.Histogram
-	highestSentTimestamp *maxTimestamp
-	pendingSamples       prometheus.Gauge
-	shardCapacity        prometheus.Gauge
-	numShards            prometheus.Gauge
-	maxNumShards         prometheus.Gauge
-	minNumShards         prometheus.Gauge
-	desiredNumShards     prometheus.Gauge
-	samplesBytesTotal    prometheus.Counter
-	metadataBytesTotal   prometheus.Counter
-	maxSamplesPerSend    prometheus.Gauge
+	samplesTotal           prometheus.Counter
+	metadataTotal          prometheus.Counter
+	failedSamplesTotal     prometheus.Counter
+	failedMetadataTotal    prometheus.Counter
+	retriedSamplesTotal    prometheus.Counter
+	retriedMetadataTotal   prometheus.Counter
+	droppedSamplesTotal    prometheus.Counter
+	suppressedSamplesTotal prometheus.Counter
+	enqueueRetriesTotal    prometheus.Counter
+	sentBatchDuration      prometheus.Histogram
+	highestSentTimestamp   *maxTimestamp
+	pendingSamples         prometheus.Gauge
+	shardCapacity          prometheus.Gauge
+	numShards              prometheus.Gauge
+	maxNumShards           prometheus.Gauge
+	minNumShards           prometheus.Gauge
+	desiredNumShards       prometheus.Gauge
+	samplesBytesTotal      prometheus.Counter
+	metadataBytesTotal     prometheus.Counter
+	maxSamplesPerSend      prometheus.Gauge
 }
 
 func newQueueManagerMetrics(r prometheus.Registerer, rn, e string) *queueManagerMetrics {
@@ -131,6 +133,13 @@ func newQueueManagerMetrics(r prometheus.Registerer, rn, e string) *queueManager
 		Help:        "Total number of samples which were dropped after being read from the WAL before being sent via remote write.",
 		ConstLabels: constLabels,
 	})
+	m.suppressedSamplesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   subsystem,
+		Name:        "samples_suppressed_total",
+		Help:        "Total number of samples suppressed before sending because they were older than or identical to the last sample sent for the same series in the same shard.",
+		ConstLabels: constLabels,
+	})
 	m.enqueueRetriesTotal = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace:   namespace,
 		Subsystem:   subsystem,
@@ -232,6 +241,7 @@ func (m *queueManagerMetrics) register() {
 			m.retriedSamplesTotal,
 			m.retriedMetadataTotal,
 			m.droppedSamplesTotal,
+			m.suppressedSamplesTotal,
 			m.enqueueRetriesTotal,
 			m.sentBatchDuration,
 			m.highestSentTimestamp,
@@ -257,6 +267,7 @@ func (m *queueManagerMetrics) unregister() {
 		m.reg.Unregister(m.retriedSamplesTotal)
 		m.reg.Unregister(m.retriedMetadataTotal)
 		m.reg.Unregister(m.droppedSamplesTotal)
+		m.reg.Unregister(m.suppressedSamplesTotal)
 		m.reg.Unregister(m.enqueueRetriesTotal)
 		m.reg.Unregister(m.sentBatchDuration)
 		m.reg.Unregister(m.highestSentTimestamp)
@@ -302,7 +313,7 @@ type QueueManager struct {
 	storeClient WriteClient
 
 	seriesMtx            sync.Mutex
-	seriesLabels         map[uint64]labels.Labels
+	seriesLabels         map[uint64]labels.Interned
 	seriesSegmentIndexes map[uint64]int
 	droppedSeries        map[uint64]struct{}
 
@@ -311,11 +322,11 @@ type QueueManager struct {
 	reshardChan chan int
 	quit        chan struct{}
 	wg          sync.WaitGroup
+	drained     atomic.Bool
 
 	samplesIn, samplesDropped, samplesOut, samplesOutDuration *ewmaRate
 
 	metrics              *queueManagerMetrics
-	interner             *pool
 	highestRecvTimestamp *maxTimestamp
 }
 
@@ -333,7 +344,6 @@ func NewQueueManager(
 	relabelConfigs []*relabel.Config,
 	client WriteClient,
 	flushDeadline time.Duration,
-	interner *pool,
 	highestRecvTimestamp *maxTimestamp,
 	sm ReadyScrapeManager,
 ) *QueueManager {
@@ -351,7 +361,7 @@ func NewQueueManager(
 		relabelConfigs: relabelConfigs,
 		storeClient:    client,
 
-		seriesLabels:         make(map[uint64]labels.Labels),
+		seriesLabels:         make(map[uint64]labels.Interned),
 		seriesSegmentIndexes: make(map[uint64]int),
 		droppedSeries:        make(map[uint64]struct{}),
 
@@ -365,7 +375,6 @@ func NewQueueManager(
 		samplesOutDuration: newEWMARate(ewmaWeight, shardUpdateDuration),
 
 		metrics:              metrics,
-		interner:             interner,
 		highestRecvTimestamp: highestRecvTimestamp,
 	}
 
@@ -468,7 +477,8 @@ outer:
 			}
 
 			if t.shards.enqueue(s.Ref, sample{
-				labels: lbls,
+				ref:    s.Ref,
+				labels: lbls.Labels(),
 				t:      s.T,
 				v:      s.V,
 			}) {
@@ -514,6 +524,10 @@ func (t *QueueManager) Stop() {
 	level.Info(t.logger).Log("msg", "Stopping remote storage...")
 	defer level.Info(t.logger).Log("msg", "Remote storage stopped.")
 
+	if t.drained.Load() {
+		// Drain already shut everything down.
+		return
+	}
 	close(t.quit)
 	t.wg.Wait()
 	// Wait for all QueueManager routines to end before stopping shards, metadata watcher, and WAL watcher. This
@@ -525,13 +539,36 @@ func (t *QueueManager) Stop() {
 		t.metadataWatcher.Stop()
 	}
 
-	// On shutdown, release the strings in the labels from the intern pool.
-	t.seriesMtx.Lock()
-	for _, labels := range t.seriesLabels {
-		t.releaseLabels(labels)
+	t.metrics.unregister()
+}
+
+// Drain gracefully shuts the queue manager down: it stops reading new data
+// from the WAL and then flushes all pending shards, waiting as long as the
+// context allows instead of the fixed flush deadline. After a successful
+// drain, Stop is a no-op. Intended to guarantee delivery before intentional
+// restarts.
+func (t *QueueManager) Drain(ctx context.Context) error {
+	if !t.drained.CAS(false, true) {
+		return errors.New("queue is already being drained")
+	}
+	level.Info(t.logger).Log("msg", "Draining remote storage queue...")
+
+	// Stop reading new WAL data before flushing so the queues cannot refill.
+	t.watcher.Stop()
+	if t.mcfg.Send {
+		t.metadataWatcher.Stop()
 	}
-	t.seriesMtx.Unlock()
+	close(t.quit)
+	t.wg.Wait()
+
+	err := t.shards.drain(ctx)
 	t.metrics.unregister()
+	if err != nil {
+		level.Error(t.logger).Log("msg", "Failed to drain remote storage queue", "err", err)
+		return err
+	}
+	level.Info(t.logger).Log("msg", "Remote storage queue drained.")
+	return nil
 }
 
 // StoreSeries keeps track of which series we know about for lookups when sending samples to remote.
@@ -548,15 +585,9 @@ func (t *QueueManager) StoreSeries(series []record.RefSeries, index int) {
 			t.droppedSeries[s.Ref] = struct{}{}
 			continue
 		}
-		t.internLabels(lbls)
-
-		// We should not ever be replacing a series labels in the map, but just
-		// in case we do we need to ensure we do not leak the replaced interned
-		// strings.
-		if orig, ok := t.seriesLabels[s.Ref]; ok {
-			t.releaseLabels(orig)
-		}
-		t.seriesLabels[s.Ref] = lbls
+		// The interned representation keeps the whole label set in a single
+		// backing string, so replacing an entry cannot leak anything.
+		t.seriesLabels[s.Ref] = labels.Intern(lbls)
 	}
 }
 
@@ -571,7 +602,6 @@ func (t *QueueManager) SeriesReset(index int) {
 	for k, v := range t.seriesSegmentIndexes {
 		if v < index {
 			delete(t.seriesSegmentIndexes, k)
-			t.releaseLabels(t.seriesLabels[k])
 			delete(t.seriesLabels, k)
 			delete(t.droppedSeries, k)
 		}
@@ -592,20 +622,6 @@ func (t *QueueManager) client() WriteClient {
 	return t.storeClient
 }
 
-func (t *QueueManager) internLabels(lbls labels.Labels) {
-	for i, l := range lbls {
-		lbls[i].Name = t.interner.intern(l.Name)
-		lbls[i].Value = t.interner.intern(l.Value)
-	}
-}
-
-func (t *QueueManager) releaseLabels(ls labels.Labels) {
-	for _, l := range ls {
-		t.interner.release(l.Name)
-		t.interner.release(l.Value)
-	}
-}
-
 // processExternalLabels merges externalLabels into ls. If ls contains
 // a label in externalLabels, the value in ls wins.
 func processExternalLabels(ls labels.Labels, externalLabels labels.Labels) labels.Labels {
@@ -786,6 +802,7 @@ func (t *QueueManager) newShards() *shards {
 }
 
 type sample struct {
+	ref    uint64
 	labels labels.Labels
 	t      int64
 	v      float64
@@ -869,6 +886,44 @@ func (s *shards) stop() {
 	}
 }
 
+// drain the shards like stop, but wait for all pending samples to be sent as
+// long as the context allows instead of the fixed flush deadline, reporting
+// progress along the way. When the context is canceled the remaining samples
+// are dropped as in a hard shutdown.
+func (s *shards) drain(ctx context.Context) error {
+	s.mtx.RLock()
+	close(s.softShutdown)
+	s.mtx.RUnlock()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, queue := range s.queues {
+		close(queue)
+	}
+
+	progress := time.NewTicker(5 * time.Second)
+	defer progress.Stop()
+	for {
+		select {
+		case <-s.done:
+			return nil
+		case <-progress.C:
+			queued := 0
+			for _, queue := range s.queues {
+				queued += len(queue)
+			}
+			level.Info(s.qm.logger).Log("msg", "Draining shards...", "queued", queued)
+		case <-ctx.Done():
+			s.hardShutdown()
+			<-s.done
+			if dropped := s.droppedOnHardShutdown.Load(); dropped > 0 {
+				level.Error(s.qm.logger).Log("msg", "Failed to drain all samples", "count", dropped)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
 // enqueue a sample.  If we are currently in the process of shutting down or resharding,
 // will return false; in this case, you should back off and retry.
 func (s *shards) enqueue(ref uint64, sample sample) bool {
@@ -908,7 +963,15 @@ func (s *shards) runShard(ctx context.Context, shardID int, queue chan sample) {
 		nPending       = 0
 		pendingSamples = allocateTimeSeries(max)
 		buf            []byte
+
+		// Highest timestamp accepted per series owned by this shard, used
+		// to suppress duplicates and samples that would go backwards, e.g.
+		// after a WAL replay overlap.
+		lastSeriesTs map[uint64]int64
 	)
+	if s.qm.cfg.EnforceInOrder {
+		lastSeriesTs = make(map[uint64]int64)
+	}
 
 	timer := time.NewTimer(time.Duration(s.qm.cfg.BatchSendDeadline))
 	stop := func() {
@@ -943,6 +1006,15 @@ func (s *shards) runShard(ctx context.Context, shardID int, queue chan sample) {
 				return
 			}
 
+			if lastSeriesTs != nil {
+				if last, ok := lastSeriesTs[sample.ref]; ok && sample.t <= last {
+					s.qm.metrics.pendingSamples.Dec()
+					s.qm.metrics.suppressedSamplesTotal.Inc()
+					continue
+				}
+				lastSeriesTs[sample.ref] = sample.t
+			}
+
 			// Number of pending samples is limited by the fact that sendSamples (via sendSamplesWithBackoff)
 			// retries endlessly, so once we reach max samples, if we can never send to the endpoint we'll
 			// stop reading from the queue. This makes it safe to reference pendingSamples by index.
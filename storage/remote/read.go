@@ -18,6 +18,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/storage"
 )
@@ -106,6 +107,47 @@ func (c *sampleAndChunkQueryableClient) ChunkQuerier(ctx context.Context, mint,
 
 // preferLocalStorage returns noop if requested timeframe can be answered completely by the local TSDB, and
 // reduces maxt if the timeframe can be partially answered by TSDB.
+// ExemplarQuerier implements storage.ExemplarQueryable by reading exemplars
+// attached to sampled remote read responses.
+func (c *sampleAndChunkQueryableClient) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	return &exemplarQuerier{
+		ctx:    ctx,
+		client: c.client,
+	}, nil
+}
+
+type exemplarQuerier struct {
+	ctx    context.Context
+	client ReadClient
+}
+
+// Select issues one remote read query per matcher set and returns the
+// exemplars carried by the resulting time series.
+func (q *exemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	var results []exemplar.QueryResult
+	for _, ms := range matchers {
+		query, err := ToQuery(start, end, ms, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "toQuery")
+		}
+		res, err := q.client.Read(q.ctx, query)
+		if err != nil {
+			return nil, errors.Wrap(err, "remote_read")
+		}
+		for _, ts := range res.Timeseries {
+			if len(ts.Exemplars) == 0 {
+				continue
+			}
+			r := exemplar.QueryResult{SeriesLabels: labelProtosToLabels(ts.Labels)}
+			for _, e := range ts.Exemplars {
+				r.Exemplars = append(r.Exemplars, exemplarProtoToExemplar(e))
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
 func (c *sampleAndChunkQueryableClient) preferLocalStorage(mint, maxt int64) (cmaxt int64, noop bool, err error) {
 	localStartTime, err := c.callback()
 	if err != nil {
@@ -32,6 +32,7 @@ import (
 type readHandler struct {
 	logger                    log.Logger
 	queryable                 storage.SampleAndChunkQueryable
+	exemplarQueryable         storage.ExemplarQueryable
 	config                    func() config.Config
 	remoteReadSampleLimit     int
 	remoteReadMaxBytesInFrame int
@@ -40,11 +41,13 @@ type readHandler struct {
 }
 
 // NewReadHandler creates a http.Handler that accepts remote read requests and
-// writes them to the provided queryable.
-func NewReadHandler(logger log.Logger, r prometheus.Registerer, queryable storage.SampleAndChunkQueryable, config func() config.Config, remoteReadSampleLimit, remoteReadConcurrencyLimit, remoteReadMaxBytesInFrame int) http.Handler {
+// writes them to the provided queryable. If exemplarQueryable is not nil,
+// exemplars for the selected series are attached to sampled responses.
+func NewReadHandler(logger log.Logger, r prometheus.Registerer, queryable storage.SampleAndChunkQueryable, exemplarQueryable storage.ExemplarQueryable, config func() config.Config, remoteReadSampleLimit, remoteReadConcurrencyLimit, remoteReadMaxBytesInFrame int) http.Handler {
 	h := &readHandler{
 		logger:                    logger,
 		queryable:                 queryable,
+		exemplarQueryable:         exemplarQueryable,
 		config:                    config,
 		remoteReadSampleLimit:     remoteReadSampleLimit,
 		remoteReadGate:            gate.New(remoteReadConcurrencyLimit),
@@ -159,6 +162,11 @@ func (h *readHandler) remoteReadSamples(
 			for _, w := range ws {
 				level.Warn(h.logger).Log("msg", "Warnings on remote read query", "err", w.Error())
 			}
+			if h.exemplarQueryable != nil {
+				if err := h.attachExemplars(ctx, query, filteredMatchers, resp.Results[i]); err != nil {
+					level.Warn(h.logger).Log("msg", "Error attaching exemplars to remote read response", "err", err.Error())
+				}
+			}
 			for _, ts := range resp.Results[i].Timeseries {
 				ts.Labels = MergeLabels(ts.Labels, sortedExternalLabels)
 			}
@@ -245,6 +253,38 @@ func (h *readHandler) remoteReadStreamedXORChunks(ctx context.Context, w http.Re
 	}
 }
 
+// attachExemplars adds the exemplars stored for the queried time range to the
+// time series of the query result they belong to. It must be called before
+// external labels are merged into the series labels.
+func (h *readHandler) attachExemplars(ctx context.Context, query *prompb.Query, matchers []*labels.Matcher, result *prompb.QueryResult) error {
+	eq, err := h.exemplarQueryable.ExemplarQuerier(ctx)
+	if err != nil {
+		return err
+	}
+	results, err := eq.Select(query.StartTimestampMs, query.EndTimestampMs, matchers)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	series := make(map[string]*prompb.TimeSeries, len(result.Timeseries))
+	for _, ts := range result.Timeseries {
+		series[labelProtosToLabels(ts.Labels).String()] = ts
+	}
+	for _, res := range results {
+		ts, ok := series[res.SeriesLabels.String()]
+		if !ok {
+			continue
+		}
+		for _, e := range res.Exemplars {
+			ts.Exemplars = append(ts.Exemplars, exemplarToExemplarProto(e))
+		}
+	}
+	return nil
+}
+
 // filterExtLabelsFromMatchers change equality matchers which match external labels
 // to a matcher that looks for an empty label,
 // as that label should not be present in the storage.
@@ -44,7 +44,7 @@ func TestSampledReadEndpoint(t *testing.T) {
 	err = suite.Run()
 	require.NoError(t, err)
 
-	h := NewReadHandler(nil, nil, suite.Storage(), func() config.Config {
+	h := NewReadHandler(nil, nil, suite.Storage(), nil, func() config.Config {
 		return config.Config{
 			GlobalConfig: config.GlobalConfig{
 				ExternalLabels: labels.Labels{
@@ -132,7 +132,7 @@ func TestStreamReadEndpoint(t *testing.T) {
 
 	require.NoError(t, suite.Run())
 
-	api := NewReadHandler(nil, nil, suite.Storage(), func() config.Config {
+	api := NewReadHandler(nil, nil, suite.Storage(), nil, func() config.Config {
 		return config.Config{
 			GlobalConfig: config.GlobalConfig{
 				ExternalLabels: labels.Labels{
@@ -174,11 +174,38 @@ func (s *Storage) ChunkQuerier(ctx context.Context, mint, maxt int64) (storage.C
 	return storage.NewMergeChunkQuerier(nil, queriers, storage.NewCompactingChunkSeriesMerger(storage.ChainedSeriesMerge)), nil
 }
 
+// ExemplarQuerier returns an exemplar querier merging the exemplars served by
+// all configured remote read endpoints.
+func (s *Storage) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	s.mtx.Lock()
+	queryables := s.queryables
+	s.mtx.Unlock()
+
+	eqs := make([]storage.ExemplarQueryable, 0, len(queryables))
+	for _, queryable := range queryables {
+		if eq, ok := queryable.(storage.ExemplarQueryable); ok {
+			eqs = append(eqs, eq)
+		}
+	}
+	return storage.NewMergeExemplarQueryable(eqs...).ExemplarQuerier(ctx)
+}
+
 // Appender implements storage.Storage.
 func (s *Storage) Appender(ctx context.Context) storage.Appender {
 	return s.rws.Appender(ctx)
 }
 
+// LowestSentTimestamp returns the lowest sent timestamp across all queues.
+func (s *Storage) LowestSentTimestamp() int64 {
+	return s.rws.LowestSentTimestamp()
+}
+
+// Drain stops reading new WAL data and flushes all pending remote write
+// queues, waiting as long as the context allows.
+func (s *Storage) Drain(ctx context.Context) error {
+	return s.rws.Drain(ctx)
+}
+
 // Close the background processing of the storage queues.
 func (s *Storage) Close() error {
 	s.mtx.Lock()
@@ -16,6 +16,7 @@ package remote
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -52,7 +53,6 @@ type WriteStorage struct {
 	queues            map[string]*QueueManager
 	samplesIn         *ewmaRate
 	flushDeadline     time.Duration
-	interner          *pool
 	scraper           ReadyScrapeManager
 
 	// For timestampTracker.
@@ -73,7 +73,6 @@ func NewWriteStorage(logger log.Logger, reg prometheus.Registerer, walDir string
 		flushDeadline:     flushDeadline,
 		samplesIn:         newEWMARate(ewmaWeight, shardUpdateDuration),
 		walDir:            walDir,
-		interner:          newPool(),
 		scraper:           sm,
 		highestTimestamp: &maxTimestamp{
 			Gauge: prometheus.NewGauge(prometheus.GaugeOpts{
@@ -166,7 +165,6 @@ func (rws *WriteStorage) ApplyConfig(conf *config.Config) error {
 			rwConf.WriteRelabelConfigs,
 			c,
 			rws.flushDeadline,
-			rws.interner,
 			rws.highestTimestamp,
 			rws.scraper,
 		)
@@ -189,6 +187,24 @@ func (rws *WriteStorage) ApplyConfig(conf *config.Config) error {
 	return nil
 }
 
+// LowestSentTimestamp returns the lowest sent timestamp across all queues.
+func (rws *WriteStorage) LowestSentTimestamp() int64 {
+	rws.mtx.Lock()
+	defer rws.mtx.Unlock()
+
+	var lowestTs int64 = math.MaxInt64
+	for _, q := range rws.queues {
+		ts := int64(q.metrics.highestSentTimestamp.Get() * 1000)
+		if ts < lowestTs {
+			lowestTs = ts
+		}
+	}
+	if len(rws.queues) == 0 {
+		lowestTs = 0
+	}
+	return lowestTs
+}
+
 // Appender implements storage.Storage.
 func (rws *WriteStorage) Appender(_ context.Context) storage.Appender {
 	return &timestampTracker{
@@ -197,6 +213,39 @@ func (rws *WriteStorage) Appender(_ context.Context) storage.Appender {
 	}
 }
 
+// Drain stops reading new WAL data on all queues and flushes their pending
+// samples, waiting as long as the context allows. Queues are drained
+// concurrently; the first error encountered is returned.
+func (rws *WriteStorage) Drain(ctx context.Context) error {
+	rws.mtx.Lock()
+	queues := make([]*QueueManager, 0, len(rws.queues))
+	for _, q := range rws.queues {
+		queues = append(queues, q)
+	}
+	rws.mtx.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		errMtx   sync.Mutex
+		firstErr error
+	)
+	for _, q := range queues {
+		wg.Add(1)
+		go func(q *QueueManager) {
+			defer wg.Done()
+			if err := q.Drain(ctx); err != nil {
+				errMtx.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMtx.Unlock()
+			}
+		}(q)
+	}
+	wg.Wait()
+	return firstErr
+}
+
 // Close closes the WriteStorage.
 func (rws *WriteStorage) Close() error {
 	rws.mtx.Lock()
@@ -16,16 +16,22 @@ package remote
 import (
 	"context"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/adolli/prometheus/pkg/exemplar"
+	"github.com/adolli/prometheus/pkg/textparse"
 	"github.com/adolli/prometheus/prompb"
+	"github.com/adolli/prometheus/scrape"
 	"github.com/adolli/prometheus/storage"
 )
 
 type writeHandler struct {
 	logger     log.Logger
 	appendable storage.Appendable
+	metadata   *writeMetadataStore
 }
 
 // NewWriteHandler creates a http.Handler that accepts remote write requests and
@@ -34,6 +40,7 @@ func NewWriteHandler(logger log.Logger, appendable storage.Appendable) http.Hand
 	return &writeHandler{
 		logger:     logger,
 		appendable: appendable,
+		metadata:   newWriteMetadataStore(),
 	}
 }
 
@@ -80,7 +87,109 @@ func (h *writeHandler) write(ctx context.Context, req *prompb.WriteRequest) (err
 				return err
 			}
 		}
+
+		for _, ep := range ts.Exemplars {
+			e := exemplarProtoToExemplar(ep)
+
+			_, err = app.AppendExemplar(0, labels, e)
+			if err == storage.ErrOutOfOrderExemplar {
+				// Duplicate and out of order exemplars are noisy with many
+				// senders, drop them without failing the whole request.
+				level.Debug(h.logger).Log("msg", "Out of order exemplar", "exemplar", e.Labels.String())
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
 	}
 
+	h.metadata.update(req.Metadata)
+
 	return nil
 }
+
+// MetadataStore returns the store holding the latest metadata received for
+// each metric family over remote write.
+func (h *writeHandler) MetadataStore() scrape.MetricMetadataStore {
+	return h.metadata
+}
+
+func exemplarProtoToExemplar(ep prompb.Exemplar) exemplar.Exemplar {
+	timestamp := ep.Timestamp
+
+	return exemplar.Exemplar{
+		Labels: labelProtosToLabels(ep.Labels),
+		Value:  ep.Value,
+		Ts:     timestamp,
+		HasTs:  timestamp != 0,
+	}
+}
+
+func metricTypeFromProtoEquivalent(t prompb.MetricMetadata_MetricType) textparse.MetricType {
+	mt := strings.ToLower(t.String())
+	return textparse.MetricType(mt)
+}
+
+// writeMetadataStore keeps the latest metadata received per metric family.
+// It implements scrape.MetricMetadataStore.
+type writeMetadataStore struct {
+	mtx      sync.RWMutex
+	metadata map[string]scrape.MetricMetadata
+}
+
+func newWriteMetadataStore() *writeMetadataStore {
+	return &writeMetadataStore{metadata: map[string]scrape.MetricMetadata{}}
+}
+
+func (s *writeMetadataStore) update(metadata []prompb.MetricMetadata) {
+	if len(metadata) == 0 {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, m := range metadata {
+		s.metadata[m.MetricFamilyName] = scrape.MetricMetadata{
+			Metric: m.MetricFamilyName,
+			Type:   metricTypeFromProtoEquivalent(m.Type),
+			Help:   m.Help,
+			Unit:   m.Unit,
+		}
+	}
+}
+
+func (s *writeMetadataStore) ListMetadata() []scrape.MetricMetadata {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	res := make([]scrape.MetricMetadata, 0, len(s.metadata))
+	for _, m := range s.metadata {
+		res = append(res, m)
+	}
+	return res
+}
+
+func (s *writeMetadataStore) GetMetadata(metric string) (scrape.MetricMetadata, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	m, ok := s.metadata[metric]
+	return m, ok
+}
+
+func (s *writeMetadataStore) SizeMetadata() (size int) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for _, m := range s.metadata {
+		size += len(m.Metric) + len(m.Type) + len(m.Help) + len(m.Unit)
+	}
+	return size
+}
+
+func (s *writeMetadataStore) LengthMetadata() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return len(s.metadata)
+}
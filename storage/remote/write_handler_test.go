@@ -25,7 +25,9 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
+	"github.com/adolli/prometheus/pkg/textparse"
 	"github.com/adolli/prometheus/prompb"
+	"github.com/adolli/prometheus/scrape"
 	"github.com/adolli/prometheus/storage"
 	"github.com/stretchr/testify/require"
 )
@@ -47,15 +49,78 @@ func TestRemoteWriteHandler(t *testing.T) {
 	require.Equal(t, http.StatusNoContent, resp.StatusCode)
 
 	i := 0
+	j := 0
 	for _, ts := range writeRequestFixture.Timeseries {
 		labels := labelProtosToLabels(ts.Labels)
 		for _, s := range ts.Samples {
 			require.Equal(t, mockSample{labels, s.Timestamp, s.Value}, appendable.samples[i])
 			i++
 		}
+
+		for _, e := range ts.Exemplars {
+			exemplarLabels := labelProtosToLabels(e.Labels)
+			require.Equal(t, mockExemplar{labels, exemplarLabels, e.Timestamp, e.Value}, appendable.exemplars[j])
+			j++
+		}
 	}
 }
 
+func TestMetadataIngestion(t *testing.T) {
+	buf, _, err := buildWriteRequest(nil, []prompb.MetricMetadata{{
+		MetricFamilyName: "test_metric",
+		Type:             prompb.MetricMetadata_COUNTER,
+		Help:             "a test counter",
+	}}, nil)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("", "", bytes.NewReader(buf))
+	require.NoError(t, err)
+
+	appendable := &mockAppendable{}
+	handler := NewWriteHandler(log.NewNopLogger(), appendable).(*writeHandler)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	resp := recorder.Result()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	store := handler.MetadataStore()
+	m, ok := store.GetMetadata("test_metric")
+	require.True(t, ok)
+	require.Equal(t, scrape.MetricMetadata{
+		Metric: "test_metric",
+		Type:   textparse.MetricTypeCounter,
+		Help:   "a test counter",
+	}, m)
+	require.Equal(t, 1, store.LengthMetadata())
+}
+
+func TestOutOfOrderExemplar(t *testing.T) {
+	buf, _, err := buildWriteRequest([]prompb.TimeSeries{{
+		Labels:    []prompb.Label{{Name: "__name__", Value: "test_metric"}},
+		Samples:   []prompb.Sample{{Value: 1, Timestamp: 1}},
+		Exemplars: []prompb.Exemplar{{Labels: []prompb.Label{{Name: "foo", Value: "bar"}}, Value: 1, Timestamp: 0}},
+	}}, nil, nil)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("", "", bytes.NewReader(buf))
+	require.NoError(t, err)
+
+	appendable := &mockAppendable{
+		latestExemplar: 100,
+	}
+	handler := NewWriteHandler(log.NewNopLogger(), appendable)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	// Out of order exemplars are not treated as errors.
+	resp := recorder.Result()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, 0, len(appendable.exemplars))
+}
+
 func TestOutOfOrder(t *testing.T) {
 	buf, _, err := buildWriteRequest([]prompb.TimeSeries{{
 		Labels:  []prompb.Label{{Name: "__name__", Value: "test_metric"}},
@@ -101,9 +166,11 @@ func TestCommitErr(t *testing.T) {
 }
 
 type mockAppendable struct {
-	latest    int64
-	samples   []mockSample
-	commitErr error
+	latest         int64
+	samples        []mockSample
+	latestExemplar int64
+	exemplars      []mockExemplar
+	commitErr      error
 }
 
 type mockSample struct {
@@ -112,6 +179,13 @@ type mockSample struct {
 	v float64
 }
 
+type mockExemplar struct {
+	l  labels.Labels
+	el labels.Labels
+	t  int64
+	v  float64
+}
+
 func (m *mockAppendable) Appender(_ context.Context) storage.Appender {
 	return m
 }
@@ -134,7 +208,12 @@ func (*mockAppendable) Rollback() error {
 	return fmt.Errorf("not implemented")
 }
 
-func (*mockAppendable) AppendExemplar(ref uint64, l labels.Labels, e exemplar.Exemplar) (uint64, error) {
-	// noop until we implement exemplars over remote write
+func (m *mockAppendable) AppendExemplar(_ uint64, l labels.Labels, e exemplar.Exemplar) (uint64, error) {
+	if e.Ts < m.latestExemplar {
+		return 0, storage.ErrOutOfOrderExemplar
+	}
+
+	m.latestExemplar = e.Ts
+	m.exemplars = append(m.exemplars, mockExemplar{l, e.Labels, e.Ts, e.Value})
 	return 0, nil
 }
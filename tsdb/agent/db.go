@@ -0,0 +1,609 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agent implements a WAL-only storage. It holds series in memory only
+// for as long as they are needed to ship samples via remote write, never
+// compacts the data into blocks and does not support local querying. It is
+// meant for remote_write-only deployments where the full TSDB footprint is
+// not wanted.
+package agent
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/adolli/prometheus/pkg/exemplar"
+	"github.com/adolli/prometheus/pkg/labels"
+	"github.com/adolli/prometheus/pkg/timestamp"
+	"github.com/adolli/prometheus/storage"
+	"github.com/adolli/prometheus/storage/remote"
+	"github.com/adolli/prometheus/tsdb/record"
+	"github.com/adolli/prometheus/tsdb/wal"
+)
+
+// ErrUnsupported is returned for operations that are not supported by the
+// WAL-only storage.
+var ErrUnsupported = errors.New("unsupported operation with WAL-only storage")
+
+// Default values for options.
+var (
+	DefaultTruncateFrequency = 2 * time.Hour
+	DefaultMinWALTime        = int64(5 * time.Minute / time.Millisecond)
+	DefaultMaxWALTime        = int64(4 * time.Hour / time.Millisecond)
+)
+
+// Options of the WAL storage.
+type Options struct {
+	// Segments (wal files) max size.
+	// WALSegmentSize <= 0, segment size is default size.
+	WALSegmentSize int
+
+	// WALCompression will turn on Snappy compression for records on the WAL.
+	WALCompression bool
+
+	// TruncateFrequency determines how frequently to truncate data from the WAL.
+	TruncateFrequency time.Duration
+
+	// Shortest and longest amount of time data can exist in the WAL before being
+	// deleted.
+	MinWALTime, MaxWALTime int64
+}
+
+// DefaultOptions used for the WAL storage. They are sane for setups using
+// millisecond-precision timestamps.
+func DefaultOptions() *Options {
+	return &Options{
+		WALSegmentSize:    wal.DefaultSegmentSize,
+		WALCompression:    false,
+		TruncateFrequency: DefaultTruncateFrequency,
+		MinWALTime:        DefaultMinWALTime,
+		MaxWALTime:        DefaultMaxWALTime,
+	}
+}
+
+func validateOptions(opts *Options) *Options {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.WALSegmentSize <= 0 {
+		opts.WALSegmentSize = wal.DefaultSegmentSize
+	}
+	if opts.TruncateFrequency <= 0 {
+		opts.TruncateFrequency = DefaultTruncateFrequency
+	}
+	if opts.MinWALTime <= 0 {
+		opts.MinWALTime = DefaultMinWALTime
+	}
+	if opts.MaxWALTime <= 0 {
+		opts.MaxWALTime = DefaultMaxWALTime
+	}
+	if opts.MaxWALTime < opts.MinWALTime {
+		opts.MaxWALTime = opts.MinWALTime
+	}
+	return opts
+}
+
+type dbMetrics struct {
+	numActiveSeries      prometheus.Gauge
+	totalAppendedSamples prometheus.Counter
+	walTruncateDuration  prometheus.Summary
+	walCorruptionsTotal  prometheus.Counter
+}
+
+func newDBMetrics(r prometheus.Registerer) *dbMetrics {
+	m := &dbMetrics{
+		numActiveSeries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "prometheus_agent_active_series",
+			Help: "Number of active series being tracked by the WAL storage.",
+		}),
+		totalAppendedSamples: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prometheus_agent_samples_appended_total",
+			Help: "Total number of samples appended to the WAL storage.",
+		}),
+		walTruncateDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name: "prometheus_agent_truncate_duration_seconds",
+			Help: "Duration of WAL truncation.",
+		}),
+		walCorruptionsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prometheus_agent_corruptions_total",
+			Help: "Total number of WAL corruptions.",
+		}),
+	}
+	if r != nil {
+		r.MustRegister(
+			m.numActiveSeries,
+			m.totalAppendedSamples,
+			m.walTruncateDuration,
+			m.walCorruptionsTotal,
+		)
+	}
+	return m
+}
+
+// DB represents a WAL-only storage. It implements storage.Storage.
+type DB struct {
+	logger log.Logger
+	opts   *Options
+	rs     *remote.Storage
+
+	wal *wal.WAL
+
+	appenderPool sync.Pool
+
+	nextRef uint64 // Accessed atomically, must be 8-byte aligned.
+
+	mtx     sync.RWMutex
+	series  *activeSeries
+	deleted map[uint64]int // Deleted series, and the last WAL segment they were seen in.
+
+	donec chan struct{}
+	stopc chan struct{}
+
+	metrics *dbMetrics
+}
+
+// Open returns a new WAL-only storage over the given directory. Samples are
+// replicated to remote storage by the remote WriteStorage watching the WAL,
+// which is also consulted to decide how much of the WAL can be truncated.
+func Open(l log.Logger, reg prometheus.Registerer, rs *remote.Storage, dir string, opts *Options) (*DB, error) {
+	opts = validateOptions(opts)
+
+	if l == nil {
+		l = log.NewNopLogger()
+	}
+
+	w, err := wal.NewSize(l, reg, filepath.Join(dir, "wal"), opts.WALSegmentSize, opts.WALCompression)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating WAL")
+	}
+
+	db := &DB{
+		logger:  l,
+		opts:    opts,
+		rs:      rs,
+		wal:     w,
+		series:  newActiveSeries(),
+		deleted: make(map[uint64]int),
+		donec:   make(chan struct{}),
+		stopc:   make(chan struct{}),
+		metrics: newDBMetrics(reg),
+	}
+	db.appenderPool.New = func() interface{} {
+		return &appender{
+			DB:             db,
+			pendingSeries:  make([]record.RefSeries, 0, 100),
+			pendingSamples: make([]record.RefSample, 0, 100),
+		}
+	}
+
+	if err := db.replayWAL(); err != nil {
+		level.Warn(db.logger).Log("msg", "Encountered WAL read error, attempting repair", "err", err)
+		db.metrics.walCorruptionsTotal.Inc()
+		if err := w.Repair(err); err != nil {
+			return nil, errors.Wrap(err, "repair corrupted WAL")
+		}
+	}
+
+	go db.run()
+	return db, nil
+}
+
+func (db *DB) replayWAL() error {
+	level.Info(db.logger).Log("msg", "Replaying WAL")
+	start := time.Now()
+
+	dir, startFrom, err := wal.LastCheckpoint(db.wal.Dir())
+	if err != nil && err != record.ErrNotFound {
+		return errors.Wrap(err, "find last checkpoint")
+	}
+
+	if err == nil {
+		sr, err := wal.NewSegmentsReader(dir)
+		if err != nil {
+			return errors.Wrap(err, "open checkpoint")
+		}
+		if err := db.loadWAL(wal.NewReader(sr)); err != nil {
+			sr.Close()
+			return errors.Wrap(err, "replay checkpoint")
+		}
+		sr.Close()
+		startFrom++
+	}
+
+	// Find the last segment.
+	first, last, err := wal.Segments(db.wal.Dir())
+	if err != nil {
+		return errors.Wrap(err, "find WAL segments")
+	}
+	if first > startFrom {
+		startFrom = first
+	}
+
+	// Backfill segments from the most recent checkpoint onwards.
+	for i := startFrom; i <= last; i++ {
+		seg, err := wal.OpenReadSegment(wal.SegmentName(db.wal.Dir(), i))
+		if err != nil {
+			return errors.Wrapf(err, "open WAL segment %d", i)
+		}
+		err = db.loadWAL(wal.NewReader(seg))
+		seg.Close()
+		if err != nil {
+			return errors.Wrapf(err, "replay WAL segment %d", i)
+		}
+	}
+
+	level.Info(db.logger).Log("msg", "WAL replay completed", "duration", time.Since(start))
+	return nil
+}
+
+func (db *DB) loadWAL(r *wal.Reader) error {
+	var dec record.Decoder
+
+	for r.Next() {
+		rec := r.Record()
+		switch dec.Type(rec) {
+		case record.Series:
+			series, err := dec.Series(rec, nil)
+			if err != nil {
+				return errors.Wrap(err, "decode series")
+			}
+			for _, s := range series {
+				if created := db.series.set(s.Labels.Hash(), &memSeries{ref: s.Ref, lset: s.Labels}); created {
+					db.metrics.numActiveSeries.Inc()
+				}
+				if s.Ref > atomic.LoadUint64(&db.nextRef) {
+					atomic.StoreUint64(&db.nextRef, s.Ref)
+				}
+			}
+		case record.Samples:
+			samples, err := dec.Samples(rec, nil)
+			if err != nil {
+				return errors.Wrap(err, "decode samples")
+			}
+			for _, s := range samples {
+				if series := db.series.getByID(s.Ref); series != nil {
+					series.updateTimestamp(s.T)
+				}
+			}
+		case record.Tombstones:
+			// Tombstones are not used by the WAL-only storage.
+		}
+	}
+	return errors.Wrap(r.Err(), "read records")
+}
+
+func (db *DB) run() {
+	defer close(db.donec)
+
+	ticker := time.NewTicker(db.opts.TruncateFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.stopc:
+			return
+		case <-ticker.C:
+			// Keep data that remote write has not shipped yet, bounded by
+			// MinWALTime and MaxWALTime.
+			ts := db.rs.LowestSentTimestamp() - db.opts.MinWALTime
+			if ts < 0 {
+				ts = 0
+			}
+			if maxTS := timestamp.FromTime(time.Now()) - db.opts.MaxWALTime; ts < maxTS {
+				ts = maxTS
+			}
+
+			level.Debug(db.logger).Log("msg", "Truncating the WAL", "ts", ts)
+			if err := db.truncate(ts); err != nil {
+				level.Warn(db.logger).Log("msg", "Failed to truncate WAL", "err", err)
+			}
+		}
+	}
+}
+
+func (db *DB) truncate(mint int64) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+
+	start := time.Now()
+
+	first, last, err := wal.Segments(db.wal.Dir())
+	if err != nil {
+		return errors.Wrap(err, "find WAL segments")
+	}
+	if last == -1 {
+		return nil
+	}
+
+	// Start a new segment so low ingestion volume instances don't have more
+	// WAL than needed.
+	if err := db.wal.NextSegment(); err != nil {
+		return errors.Wrap(err, "next segment")
+	}
+
+	// The most recent segment is never considered for the checkpoint.
+	last--
+	if last < 0 {
+		return nil
+	}
+
+	// Garbage collect series that have not received a sample since mint.
+	for _, ref := range db.series.gc(mint) {
+		db.deleted[ref] = last
+		db.metrics.numActiveSeries.Dec()
+	}
+
+	keep := func(id uint64) bool {
+		if db.series.getByID(id) != nil {
+			return true
+		}
+		seg, ok := db.deleted[id]
+		return ok && seg >= first
+	}
+	if _, err = wal.Checkpoint(db.logger, db.wal, first, last, keep, mint); err != nil {
+		return errors.Wrap(err, "create checkpoint")
+	}
+	if err := db.wal.Truncate(last + 1); err != nil {
+		// If truncating fails, we'll just try it again at the next checkpoint.
+		level.Warn(db.logger).Log("msg", "Truncating segments failed", "err", err)
+	}
+
+	// The checkpoint is written and segments before it are truncated, so we
+	// no longer need to track deleted series that were in those segments.
+	for ref, segment := range db.deleted {
+		if segment < first {
+			delete(db.deleted, ref)
+		}
+	}
+
+	if err := wal.DeleteCheckpoints(db.wal.Dir(), last); err != nil {
+		// Leftover old checkpoints do not cause problems down the line beyond
+		// occupying disk space.
+		level.Warn(db.logger).Log("msg", "Delete old checkpoints", "err", err)
+	}
+
+	db.metrics.walTruncateDuration.Observe(time.Since(start).Seconds())
+	level.Info(db.logger).Log("msg", "WAL checkpoint complete", "first", first, "last", last, "duration", time.Since(start))
+	return nil
+}
+
+// StartTime implements the Storage interface.
+func (db *DB) StartTime() (int64, error) {
+	return int64(math.MaxInt64), nil
+}
+
+// Querier implements the Storage interface.
+func (db *DB) Querier(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+	return nil, ErrUnsupported
+}
+
+// ChunkQuerier implements the Storage interface.
+func (db *DB) ChunkQuerier(ctx context.Context, mint, maxt int64) (storage.ChunkQuerier, error) {
+	return nil, ErrUnsupported
+}
+
+// ExemplarQuerier implements the storage.ExemplarQueryable interface.
+func (db *DB) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	return nil, ErrUnsupported
+}
+
+// Appender implements the Storage interface.
+func (db *DB) Appender(_ context.Context) storage.Appender {
+	return db.appenderPool.Get().(storage.Appender)
+}
+
+// Close implements the Storage interface.
+func (db *DB) Close() error {
+	close(db.stopc)
+	<-db.donec
+	return db.wal.Close()
+}
+
+// memSeries is a chunkless version of tsdb.memSeries.
+type memSeries struct {
+	sync.Mutex
+
+	ref    uint64
+	lset   labels.Labels
+	lastTs int64
+}
+
+func (s *memSeries) updateTimestamp(ts int64) {
+	s.Lock()
+	defer s.Unlock()
+	if ts > s.lastTs {
+		s.lastTs = ts
+	}
+}
+
+// activeSeries holds the set of series that may still receive samples.
+type activeSeries struct {
+	mtx    sync.RWMutex
+	series map[uint64]*memSeries   // Lookup by reference.
+	hashes map[uint64][]*memSeries // Lookup by labels hash.
+}
+
+func newActiveSeries() *activeSeries {
+	return &activeSeries{
+		series: make(map[uint64]*memSeries),
+		hashes: make(map[uint64][]*memSeries),
+	}
+}
+
+func (s *activeSeries) getByID(ref uint64) *memSeries {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.series[ref]
+}
+
+func (s *activeSeries) getByHash(hash uint64, lset labels.Labels) *memSeries {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for _, series := range s.hashes[hash] {
+		if labels.Equal(series.lset, lset) {
+			return series
+		}
+	}
+	return nil
+}
+
+// set adds the series if no series with the same labels exists yet. It
+// returns whether the series was added.
+func (s *activeSeries) set(hash uint64, series *memSeries) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, existing := range s.hashes[hash] {
+		if labels.Equal(existing.lset, series.lset) {
+			return false
+		}
+	}
+	s.series[series.ref] = series
+	s.hashes[hash] = append(s.hashes[hash], series)
+	return true
+}
+
+// gc removes series that have not received a sample since mint and returns
+// their references.
+func (s *activeSeries) gc(mint int64) []uint64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var deleted []uint64
+	for hash, all := range s.hashes {
+		kept := all[:0]
+		for _, series := range all {
+			series.Lock()
+			stale := series.lastTs < mint
+			series.Unlock()
+			if stale {
+				deleted = append(deleted, series.ref)
+				delete(s.series, series.ref)
+				continue
+			}
+			kept = append(kept, series)
+		}
+		if len(kept) == 0 {
+			delete(s.hashes, hash)
+		} else {
+			s.hashes[hash] = kept
+		}
+	}
+	return deleted
+}
+
+type appender struct {
+	*DB
+
+	pendingSeries  []record.RefSeries
+	pendingSamples []record.RefSample
+	sampleSeries   []*memSeries
+}
+
+// Append implements the storage.Appender interface.
+func (a *appender) Append(ref uint64, l labels.Labels, t int64, v float64) (uint64, error) {
+	series := a.series.getByID(ref)
+	if series == nil {
+		l = l.WithoutEmpty()
+		if len(l) == 0 {
+			return 0, errors.New("empty labelset")
+		}
+
+		hash := l.Hash()
+		series = a.series.getByHash(hash, l)
+		if series == nil {
+			series = &memSeries{ref: atomic.AddUint64(&a.nextRef, 1), lset: l}
+			if a.series.set(hash, series) {
+				a.metrics.numActiveSeries.Inc()
+				a.pendingSeries = append(a.pendingSeries, record.RefSeries{
+					Ref:    series.ref,
+					Labels: l,
+				})
+			} else {
+				series = a.series.getByHash(hash, l)
+			}
+		}
+	}
+
+	a.pendingSamples = append(a.pendingSamples, record.RefSample{
+		Ref: series.ref,
+		T:   t,
+		V:   v,
+	})
+	a.sampleSeries = append(a.sampleSeries, series)
+
+	a.metrics.totalAppendedSamples.Inc()
+	return series.ref, nil
+}
+
+// AppendExemplar implements the storage.ExemplarAppender interface. The WAL
+// has no exemplar record, so exemplars are silently dropped.
+func (a *appender) AppendExemplar(ref uint64, l labels.Labels, e exemplar.Exemplar) (uint64, error) {
+	return 0, nil
+}
+
+// Commit implements the storage.Appender interface.
+func (a *appender) Commit() error {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+
+	var (
+		enc record.Encoder
+		buf []byte
+	)
+
+	if len(a.pendingSeries) > 0 {
+		buf = enc.Series(a.pendingSeries, buf)
+		if err := a.wal.Log(buf); err != nil {
+			return errors.Wrap(err, "log series")
+		}
+		buf = buf[:0]
+	}
+	if len(a.pendingSamples) > 0 {
+		buf = enc.Samples(a.pendingSamples, buf)
+		if err := a.wal.Log(buf); err != nil {
+			return errors.Wrap(err, "log samples")
+		}
+	}
+
+	for i, s := range a.pendingSamples {
+		a.sampleSeries[i].updateTimestamp(s.T)
+	}
+
+	a.clearData()
+	a.appenderPool.Put(a)
+	return nil
+}
+
+// Rollback implements the storage.Appender interface.
+func (a *appender) Rollback() error {
+	// Series records are kept, like in the TSDB head, so the WAL still has
+	// the series of the already created references.
+	a.clearData()
+	a.appenderPool.Put(a)
+	return nil
+}
+
+func (a *appender) clearData() {
+	a.pendingSeries = a.pendingSeries[:0]
+	a.pendingSamples = a.pendingSamples[:0]
+	a.sampleSeries = a.sampleSeries[:0]
+}
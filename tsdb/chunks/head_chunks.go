@@ -70,6 +70,12 @@ const (
 	DefaultWriteBufferSize = 4 * 1024 * 1024 // 4 MiB.
 )
 
+// quarantineDirname is the directory inside the head chunks directory into
+// which corrupted chunk files are moved. Its name is not parseable as a file
+// sequence number, so quarantined files are ignored when the directory is
+// opened again.
+const quarantineDirname = "quarantine"
+
 // CorruptionErr is an error that's returned when corruption is encountered.
 type CorruptionErr struct {
 	Dir       string
@@ -772,6 +778,144 @@ func (cdm *ChunkDiskMapper) DeleteCorrupted(originalErr error) error {
 	return cdm.deleteFiles(segs)
 }
 
+// QuarantineCorrupted moves the corrupt head chunk file and all the files
+// following it into the quarantine directory so that they are kept for
+// inspection but are no longer loaded. The data they held is recovered from
+// the WAL on the next startup where the WAL still covers it.
+func (cdm *ChunkDiskMapper) QuarantineCorrupted(originalErr error) error {
+	err := errors.Cause(originalErr) // So that we can pick up errors even if wrapped.
+	cerr, ok := err.(*CorruptionErr)
+	if !ok {
+		return errors.Wrap(originalErr, "cannot handle error")
+	}
+
+	// Quarantine all the head chunk files following the corrupt head chunk
+	// file (including the corrupt file). The file currently being appended
+	// to is left alone so that the write path stays usable.
+	segs := []int{}
+	cdm.readPathMtx.RLock()
+	for seg := range cdm.mmappedChunkFiles {
+		if seg >= cerr.FileIndex && seg != cdm.curFileSequence {
+			segs = append(segs, seg)
+		}
+	}
+	cdm.readPathMtx.RUnlock()
+	sort.Ints(segs)
+
+	return cdm.quarantineFiles(segs)
+}
+
+func (cdm *ChunkDiskMapper) quarantineFiles(segs []int) error {
+	quarantineDir := filepath.Join(cdm.dir.Name(), quarantineDirname)
+	if err := os.MkdirAll(quarantineDir, 0777); err != nil {
+		return errors.Wrap(err, "create quarantine directory")
+	}
+
+	cdm.readPathMtx.Lock()
+	for _, seq := range segs {
+		if err := cdm.closers[seq].Close(); err != nil {
+			cdm.readPathMtx.Unlock()
+			return err
+		}
+		delete(cdm.mmappedChunkFiles, seq)
+		delete(cdm.closers, seq)
+	}
+	cdm.readPathMtx.Unlock()
+
+	// The renames are done separately to not block the readPathMtx for long.
+	for _, seq := range segs {
+		fn := segmentFile(cdm.dir.Name(), seq)
+		if err := fileutil.Rename(fn, filepath.Join(quarantineDir, filepath.Base(fn))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyChecksums re-reads all the chunks in the completed head chunk files
+// and verifies their checksums. The file currently being written to is
+// skipped as it is still changing. It returns the first corruption
+// encountered, if any, and is safe to call concurrently with appends.
+func (cdm *ChunkDiskMapper) VerifyChecksums() error {
+	cdm.readPathMtx.RLock()
+	defer cdm.readPathMtx.RUnlock()
+
+	if cdm.closed {
+		return ErrChunkDiskMapperClosed
+	}
+
+	segIDs := make([]int, 0, len(cdm.mmappedChunkFiles))
+	for seg := range cdm.mmappedChunkFiles {
+		if seg != cdm.curFileSequence {
+			segIDs = append(segIDs, seg)
+		}
+	}
+	sort.Ints(segIDs)
+
+	chkCRC32 := newCRC32()
+	for _, segID := range segIDs {
+		if err := cdm.verifySegmentChecksums(segID, cdm.mmappedChunkFiles[segID], chkCRC32); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cdm *ChunkDiskMapper) verifySegmentChecksums(segID int, mmapFile *mmappedChunkFile, chkCRC32 hash.Hash) error {
+	fileEnd := mmapFile.byteSlice.Len()
+	idx := HeadChunkFileHeaderSize
+	for idx < fileEnd {
+		if fileEnd-idx < MaxHeadChunkMetaSize {
+			// The remainder is the zero padding marking the end of the file
+			// content; anything else is caught during startup iteration.
+			break
+		}
+
+		startIdx := idx
+		seriesRef := binary.BigEndian.Uint64(mmapFile.byteSlice.Range(idx, idx+SeriesRefSize))
+		idx += SeriesRefSize
+		mint := int64(binary.BigEndian.Uint64(mmapFile.byteSlice.Range(idx, idx+MintMaxtSize)))
+		idx += MintMaxtSize
+		maxt := int64(binary.BigEndian.Uint64(mmapFile.byteSlice.Range(idx, idx+MintMaxtSize)))
+		idx += MintMaxtSize
+
+		// Series refs start from 1, so a zero header marks the end of the
+		// preallocated file data.
+		if seriesRef == 0 && mint == 0 && maxt == 0 {
+			break
+		}
+
+		idx += ChunkEncodingSize // Skip encoding.
+		dataLen, n := binary.Uvarint(mmapFile.byteSlice.Range(idx, idx+MaxChunkLengthFieldSize))
+		idx += n
+		idx += int(dataLen) // Skip the data.
+
+		if idx+CRCSize > fileEnd {
+			return &CorruptionErr{
+				Dir:       cdm.dir.Name(),
+				FileIndex: segID,
+				Err:       errors.Errorf("head chunk file doesn't include enough bytes to read the last chunk data - required:%v, available:%v, file:%d", idx+CRCSize, fileEnd, segID),
+			}
+		}
+
+		sum := mmapFile.byteSlice.Range(idx, idx+CRCSize)
+		chkCRC32.Reset()
+		if _, err := chkCRC32.Write(mmapFile.byteSlice.Range(startIdx, idx)); err != nil {
+			return err
+		}
+		if act := chkCRC32.Sum(nil); !bytes.Equal(act, sum) {
+			return &CorruptionErr{
+				Dir:       cdm.dir.Name(),
+				FileIndex: segID,
+				Err:       errors.Errorf("checksum mismatch expected:%x, actual:%x", sum, act),
+			}
+		}
+		idx += CRCSize
+	}
+	return nil
+}
+
 // Size returns the size of the chunk files.
 func (cdm *ChunkDiskMapper) Size() (int64, error) {
 	return fileutil.DirSize(cdm.dir.Name())
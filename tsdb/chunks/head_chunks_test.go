@@ -19,6 +19,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -411,6 +412,58 @@ func TestHeadReadWriter_ReadRepairOnEmptyLastFile(t *testing.T) {
 
 }
 
+func TestHeadReadWriter_VerifyChecksumsQuarantine(t *testing.T) {
+	hrw := testChunkDiskMapper(t)
+	defer func() {
+		require.NoError(t, hrw.Close())
+	}()
+
+	timeRange := 0
+	addChunk := func() {
+		step := 100
+		mint, maxt := timeRange+1, timeRange+step-1
+		_, err := hrw.WriteChunk(1, int64(mint), int64(maxt), randomChunk(t))
+		require.NoError(t, err)
+		timeRange += step
+	}
+
+	addChunk() // File 1.
+	require.NoError(t, hrw.CutNewFile())
+	addChunk() // File 2.
+	require.NoError(t, hrw.CutNewFile())
+	addChunk() // File 3 stays the file being appended to.
+
+	require.NoError(t, hrw.VerifyChecksums())
+
+	// Corrupt the first file by overwriting part of the series ref of its
+	// first chunk.
+	f, err := os.OpenFile(segmentFile(hrw.dir.Name(), 1), os.O_WRONLY, 0666)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{67, 88}, HeadChunkFileHeaderSize+2)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	err = hrw.VerifyChecksums()
+	require.Error(t, err)
+	cerr, ok := err.(*CorruptionErr)
+	require.True(t, ok)
+	require.Equal(t, 1, cerr.FileIndex)
+
+	require.NoError(t, hrw.QuarantineCorrupted(err))
+
+	// Files 1 and 2 are quarantined, the file being appended to is left alone.
+	require.Equal(t, 1, len(hrw.mmappedChunkFiles))
+	_, ok = hrw.mmappedChunkFiles[3]
+	require.True(t, ok)
+
+	quarantined, err := ioutil.ReadDir(filepath.Join(hrw.dir.Name(), quarantineDirname))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(quarantined))
+
+	// A subsequent scan of the remaining files is clean.
+	require.NoError(t, hrw.VerifyChecksums())
+}
+
 func testChunkDiskMapper(t *testing.T) *ChunkDiskMapper {
 	tmpdir, err := ioutil.TempDir("", "data")
 	require.NoError(t, err)
@@ -76,6 +76,7 @@ func DefaultOptions() *Options {
 		NoLockfile:                false,
 		AllowOverlappingBlocks:    false,
 		WALCompression:            false,
+		WALSyncPolicy:             wal.DefaultSyncPolicy(),
 		StripeSize:                DefaultStripeSize,
 		HeadChunksWriteBufferSize: chunks.DefaultWriteBufferSize,
 	}
@@ -111,6 +112,10 @@ type Options struct {
 	// WALCompression will turn on Snappy compression for records on the WAL.
 	WALCompression bool
 
+	// WALSyncPolicy controls when the WAL fsyncs its active segment beyond
+	// segment rotation and close.
+	WALSyncPolicy wal.SyncPolicy
+
 	// StripeSize is the size in entries of the series hash map. Reducing the size will save memory but impact performance.
 	StripeSize int
 
@@ -550,6 +555,9 @@ func validateOpts(opts *Options, rngs []int64) (*Options, []int64) {
 	if opts.HeadChunksWriteBufferSize <= 0 {
 		opts.HeadChunksWriteBufferSize = chunks.DefaultWriteBufferSize
 	}
+	if opts.WALSyncPolicy.Mode == "" {
+		opts.WALSyncPolicy = wal.DefaultSyncPolicy()
+	}
 	if opts.MinBlockDuration <= 0 {
 		opts.MinBlockDuration = DefaultBlockDuration
 	}
@@ -654,7 +662,7 @@ func open(dir string, l log.Logger, r prometheus.Registerer, opts *Options, rngs
 		if opts.WALSegmentSize > 0 {
 			segmentSize = opts.WALSegmentSize
 		}
-		wlog, err = wal.NewSize(l, r, walDir, segmentSize, opts.WALCompression)
+		wlog, err = wal.NewSizeWithSyncPolicy(l, r, walDir, segmentSize, opts.WALCompression, opts.WALSyncPolicy)
 		if err != nil {
 			return nil, err
 		}
@@ -1130,17 +1138,41 @@ func deletableBlocks(db *DB, blocks []*Block) map[ulid.ULID]struct{} {
 		}
 	}
 
+	retention := make(map[ulid.ULID]struct{})
 	for ulid := range BeyondTimeRetention(db, blocks) {
-		deletable[ulid] = struct{}{}
+		retention[ulid] = struct{}{}
 	}
 
 	for ulid := range BeyondSizeRetention(db, blocks) {
-		deletable[ulid] = struct{}{}
+		retention[ulid] = struct{}{}
+	}
+
+	// Pinned blocks are exempt from retention based deletion. Blocks that
+	// were already compacted into a new one are deleted regardless.
+	for _, block := range blocks {
+		if _, ok := retention[block.Meta().ULID]; !ok {
+			continue
+		}
+		if BlockPinned(block.Dir()) {
+			level.Debug(db.logger).Log("msg", "Skipping pinned block for retention", "block", block.Meta().ULID)
+			continue
+		}
+		deletable[block.Meta().ULID] = struct{}{}
 	}
 
 	return deletable
 }
 
+// pinnedBlockMarker is the name of the file that marks a block as pinned,
+// i.e. excluded from retention based deletion.
+const pinnedBlockMarker = "pinned"
+
+// BlockPinned returns whether the block directory carries the pinned marker.
+func BlockPinned(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, pinnedBlockMarker))
+	return err == nil
+}
+
 // BeyondTimeRetention returns those blocks which are beyond the time retention
 // set in the db options.
 func BeyondTimeRetention(db *DB, blocks []*Block) (deletable map[ulid.ULID]struct{}) {
@@ -1224,6 +1256,72 @@ func (db *DB) deleteBlocks(blocks map[ulid.ULID]*Block) error {
 	return nil
 }
 
+// DeleteBlock deletes the block with the given ID from the disk and unloads
+// it. The ID must reference a currently loaded block.
+func (db *DB) DeleteBlock(id string) error {
+	bid, err := ulid.ParseStrict(id)
+	if err != nil {
+		return errors.Wrapf(err, "invalid block ID %q", id)
+	}
+
+	db.mtx.Lock()
+	var (
+		block     *Block
+		remaining []*Block
+	)
+	for _, b := range db.blocks {
+		if b.Meta().ULID == bid {
+			block = b
+			continue
+		}
+		remaining = append(remaining, b)
+	}
+	if block == nil {
+		db.mtx.Unlock()
+		return errors.Errorf("block %s not found", id)
+	}
+	db.blocks = remaining
+	db.mtx.Unlock()
+
+	return db.deleteBlocks(map[ulid.ULID]*Block{bid: block})
+}
+
+// PinBlock marks the block with the given ID as pinned, excluding it from
+// retention based deletion until it is unpinned again. The marker is stored
+// in the block directory and thus survives restarts.
+func (db *DB) PinBlock(id string) error { return db.setBlockPinned(id, true) }
+
+// UnpinBlock removes the pinned marker from the block with the given ID,
+// making it subject to retention again.
+func (db *DB) UnpinBlock(id string) error { return db.setBlockPinned(id, false) }
+
+func (db *DB) setBlockPinned(id string, pinned bool) error {
+	bid, err := ulid.ParseStrict(id)
+	if err != nil {
+		return errors.Wrapf(err, "invalid block ID %q", id)
+	}
+
+	db.mtx.RLock()
+	block, ok := getBlock(db.blocks, bid)
+	db.mtx.RUnlock()
+	if !ok {
+		return errors.Errorf("block %s not found", id)
+	}
+
+	marker := filepath.Join(block.Dir(), pinnedBlockMarker)
+	if !pinned {
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "unpin block %s", id)
+		}
+		return nil
+	}
+	f, err := os.OpenFile(marker, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return errors.Wrapf(err, "pin block %s", id)
+	}
+	return f.Close()
+}
+
 // validateBlockSequence returns error if given block meta files indicate that some blocks overlaps within sequence.
 func validateBlockSequence(bs []*Block) error {
 	if len(bs) <= 1 {
@@ -1298,6 +1298,63 @@ func (*mockCompactorFailing) Compact(string, []string, []*Block) (ulid.ULID, err
 	return ulid.ULID{}, nil
 }
 
+func TestDeleteBlock(t *testing.T) {
+	db := openTestDB(t, nil, []int64{1000})
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	blocks := []*BlockMeta{
+		{MinTime: 500, MaxTime: 900},
+		{MinTime: 1000, MaxTime: 1500},
+	}
+	for _, m := range blocks {
+		createBlock(t, db.Dir(), genSeries(10, 10, m.MinTime, m.MaxTime))
+	}
+	require.NoError(t, db.reloadBlocks())
+	require.Equal(t, len(blocks), len(db.Blocks()))
+
+	require.Error(t, db.DeleteBlock("not-a-ulid"))
+
+	id := db.Blocks()[0].Meta().ULID
+	require.NoError(t, db.DeleteBlock(id.String()))
+	require.Equal(t, 1, len(db.Blocks()))
+
+	_, err := os.Stat(filepath.Join(db.Dir(), id.String()))
+	require.True(t, os.IsNotExist(err), "block dir should be gone")
+
+	require.Error(t, db.DeleteBlock(id.String()), "deleting a deleted block should fail")
+}
+
+func TestPinnedBlockRetention(t *testing.T) {
+	db := openTestDB(t, nil, []int64{1000})
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	blocks := []*BlockMeta{
+		{MinTime: 500, MaxTime: 900}, // Oldest block.
+		{MinTime: 1000, MaxTime: 1500},
+		{MinTime: 1500, MaxTime: 2000}, // Newest block.
+	}
+	for _, m := range blocks {
+		createBlock(t, db.Dir(), genSeries(10, 10, m.MinTime, m.MaxTime))
+	}
+	require.NoError(t, db.reloadBlocks())
+	require.Equal(t, len(blocks), len(db.Blocks()))
+
+	// Pin the oldest block, which would otherwise fall out of retention.
+	require.NoError(t, db.PinBlock(db.Blocks()[0].Meta().ULID.String()))
+
+	db.opts.RetentionDuration = blocks[2].MaxTime - blocks[1].MinTime
+	require.NoError(t, db.reloadBlocks())
+	require.Equal(t, len(blocks), len(db.Blocks()), "pinned block must survive retention")
+
+	require.NoError(t, db.UnpinBlock(db.Blocks()[0].Meta().ULID.String()))
+	require.NoError(t, db.reloadBlocks())
+	require.Equal(t, 2, len(db.Blocks()), "unpinned block must be subject to retention again")
+}
+
 func TestTimeRetention(t *testing.T) {
 	db := openTestDB(t, nil, []int64{1000})
 	defer func() {
@@ -97,11 +97,15 @@ type Head struct {
 
 	cardinalityMutex      sync.Mutex
 	cardinalityCache      *index.PostingsStats // Posting stats cache which will expire after 30sec.
-	lastPostingsStatsCall time.Duration        // Last posting stats call (PostingsCardinalityStats()) time for caching.
+	lastPostingsStatsCall  time.Duration       // Last posting stats call (PostingsCardinalityStats()) time for caching.
+	lastPostingsStatsLimit int                 // Limit used for the cached postings stats.
 
 	// chunkDiskMapper is used to write and read Head chunks to/from disk.
 	chunkDiskMapper *chunks.ChunkDiskMapper
 
+	// Terminates the background chunk integrity scanner, if it is running.
+	scannerStopc, scannerDonec chan struct{}
+
 	closedMtx sync.Mutex
 	closed    bool
 }
@@ -156,6 +160,7 @@ type headMetrics struct {
 	checkpointCreationFail   prometheus.Counter
 	checkpointCreationTotal  prometheus.Counter
 	mmapChunkCorruptionTotal prometheus.Counter
+	mmapChunkIntegrityScans  prometheus.Counter
 }
 
 func newHeadMetrics(h *Head, r prometheus.Registerer) *headMetrics {
@@ -254,6 +259,10 @@ func newHeadMetrics(h *Head, r prometheus.Registerer) *headMetrics {
 			Name: "prometheus_tsdb_mmap_chunk_corruptions_total",
 			Help: "Total number of memory-mapped chunk corruptions.",
 		}),
+		mmapChunkIntegrityScans: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prometheus_tsdb_mmap_chunk_integrity_scans_total",
+			Help: "Total number of background integrity scans of the on-disk memory-mapped chunk files.",
+		}),
 	}
 
 	if r != nil {
@@ -281,6 +290,7 @@ func newHeadMetrics(h *Head, r prometheus.Registerer) *headMetrics {
 			m.checkpointCreationFail,
 			m.checkpointCreationTotal,
 			m.mmapChunkCorruptionTotal,
+			m.mmapChunkIntegrityScans,
 			// Metrics bound to functions and not needed in tests
 			// can be created and registered on the spot.
 			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
@@ -314,19 +324,20 @@ func newHeadMetrics(h *Head, r prometheus.Registerer) *headMetrics {
 
 const cardinalityCacheExpirationTime = time.Duration(30) * time.Second
 
-// PostingsCardinalityStats returns top 10 highest cardinality stats By label and value names.
-func (h *Head) PostingsCardinalityStats(statsByLabelName string) *index.PostingsStats {
+// PostingsCardinalityStats returns the limit highest cardinality stats by label and value names.
+func (h *Head) PostingsCardinalityStats(statsByLabelName string, limit int) *index.PostingsStats {
 	h.cardinalityMutex.Lock()
 	defer h.cardinalityMutex.Unlock()
 	currentTime := time.Duration(time.Now().Unix()) * time.Second
 	seconds := currentTime - h.lastPostingsStatsCall
-	if seconds > cardinalityCacheExpirationTime {
+	if seconds > cardinalityCacheExpirationTime || limit != h.lastPostingsStatsLimit {
 		h.cardinalityCache = nil
 	}
 	if h.cardinalityCache != nil {
 		return h.cardinalityCache
 	}
-	h.cardinalityCache = h.postings.Stats(statsByLabelName)
+	h.cardinalityCache = h.postings.Stats(statsByLabelName, limit)
+	h.lastPostingsStatsLimit = limit
 	h.lastPostingsStatsCall = time.Duration(time.Now().Unix()) * time.Second
 
 	return h.cardinalityCache
@@ -708,6 +719,7 @@ func (h *Head) Init(minValidTime int64) error {
 	}
 
 	level.Info(h.logger).Log("msg", "On-disk memory mappable chunks replay completed", "duration", time.Since(start).String())
+	h.startChunkIntegrityScanner()
 	if h.wal == nil {
 		level.Info(h.logger).Log("msg", "WAL not found")
 		return nil
@@ -815,17 +827,21 @@ func (h *Head) loadMmappedChunks() (map[uint64][]*mmappedChunk, error) {
 	return mmappedChunks, nil
 }
 
-// removeCorruptedMmappedChunks attempts to delete the corrupted mmapped chunks and if it fails, it clears all the previously
-// loaded mmapped chunks.
+// removeCorruptedMmappedChunks attempts to quarantine the corrupted mmapped chunks and if it fails, it clears all the previously
+// loaded mmapped chunks. The affected series are rebuilt from the WAL during
+// the replay that follows, as far as the WAL still covers their samples.
 func (h *Head) removeCorruptedMmappedChunks(err error) map[uint64][]*mmappedChunk {
-	level.Info(h.logger).Log("msg", "Deleting mmapped chunk files")
+	level.Info(h.logger).Log("msg", "Quarantining mmapped chunk files")
 
-	if err := h.chunkDiskMapper.DeleteCorrupted(err); err != nil {
-		level.Info(h.logger).Log("msg", "Deletion of mmap chunk files failed, discarding chunk files completely", "err", err)
+	if qerr := h.chunkDiskMapper.QuarantineCorrupted(err); qerr != nil {
+		level.Info(h.logger).Log("msg", "Quarantining of mmap chunk files failed, deleting chunk files instead", "err", qerr)
+		if derr := h.chunkDiskMapper.DeleteCorrupted(err); derr != nil {
+			level.Error(h.logger).Log("msg", "Deletion of mmap chunk files failed, discarding chunk files completely", "err", derr)
+		}
 		return map[uint64][]*mmappedChunk{}
 	}
 
-	level.Info(h.logger).Log("msg", "Deletion of mmap chunk files successful, reattempting m-mapping the on-disk chunks")
+	level.Info(h.logger).Log("msg", "Quarantining of mmap chunk files successful, reattempting m-mapping the on-disk chunks")
 	mmappedChunks, err := h.loadMmappedChunks()
 	if err != nil {
 		level.Error(h.logger).Log("msg", "Loading on-disk chunks failed, discarding chunk files completely", "err", err)
@@ -835,6 +851,48 @@ func (h *Head) removeCorruptedMmappedChunks(err error) map[uint64][]*mmappedChun
 	return mmappedChunks
 }
 
+// headChunkScanInterval is how often the background scanner re-verifies the
+// checksums of the completed on-disk head chunk files.
+const headChunkScanInterval = 1 * time.Hour
+
+// startChunkIntegrityScanner starts a goroutine that periodically verifies
+// the checksums of the completed head chunk files. Corrupted files and the
+// files following them are quarantined; the chunks they held are served with
+// a corruption error until the next restart rebuilds the affected series
+// from the WAL.
+func (h *Head) startChunkIntegrityScanner() {
+	h.closedMtx.Lock()
+	defer h.closedMtx.Unlock()
+	if h.closed || h.scannerStopc != nil {
+		return
+	}
+	h.scannerStopc = make(chan struct{})
+	h.scannerDonec = make(chan struct{})
+
+	go func() {
+		defer close(h.scannerDonec)
+		ticker := time.NewTicker(headChunkScanInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.metrics.mmapChunkIntegrityScans.Inc()
+				err := h.chunkDiskMapper.VerifyChecksums()
+				if err == nil {
+					continue
+				}
+				level.Error(h.logger).Log("msg", "Corruption detected in mmapped chunk files, quarantining", "err", err)
+				h.metrics.mmapChunkCorruptionTotal.Inc()
+				if err := h.chunkDiskMapper.QuarantineCorrupted(err); err != nil {
+					level.Error(h.logger).Log("msg", "Quarantining of mmap chunk files failed", "err", err)
+				}
+			case <-h.scannerStopc:
+				return
+			}
+		}
+	}()
+}
+
 // Truncate removes old data before mint from the head and WAL.
 func (h *Head) Truncate(mint int64) (err error) {
 	initialize := h.MinTime() == math.MaxInt64
@@ -999,12 +1057,12 @@ type Stats struct {
 
 // Stats returns important current HEAD statistics. Note that it is expensive to
 // calculate these.
-func (h *Head) Stats(statsByLabelName string) *Stats {
+func (h *Head) Stats(statsByLabelName string, limit int) *Stats {
 	return &Stats{
 		NumSeries:         h.NumSeries(),
 		MaxTime:           h.MaxTime(),
 		MinTime:           h.MinTime(),
-		IndexPostingStats: h.PostingsCardinalityStats(statsByLabelName),
+		IndexPostingStats: h.PostingsCardinalityStats(statsByLabelName, limit),
 	}
 }
 
@@ -1617,6 +1675,11 @@ func (h *Head) Close() error {
 	h.closedMtx.Lock()
 	defer h.closedMtx.Unlock()
 	h.closed = true
+	if h.scannerStopc != nil {
+		close(h.scannerStopc)
+		<-h.scannerDonec
+		h.scannerStopc = nil
+	}
 	errs := tsdb_errors.NewMulti(h.chunkDiskMapper.Close())
 	if h.wal != nil {
 		errs.Add(h.wal.Close())
@@ -1383,11 +1383,22 @@ func TestHeadReadWriterRepair(t *testing.T) {
 	}
 
 	// Verify that there are 3 segment files after the repair.
-	// The segments from the corrupt segment should be removed.
+	// The segments from the corrupt segment onwards should have been moved
+	// into the quarantine directory.
 	{
 		files, err := ioutil.ReadDir(mmappedChunksDir(dir))
 		require.NoError(t, err)
-		require.Equal(t, 3, len(files))
+		names := []string{}
+		for _, f := range files {
+			if !f.IsDir() {
+				names = append(names, f.Name())
+			}
+		}
+		require.Equal(t, 3, len(names))
+
+		quarantined, err := ioutil.ReadDir(filepath.Join(mmappedChunksDir(dir), "quarantine"))
+		require.NoError(t, err)
+		require.Equal(t, 4, len(quarantined))
 	}
 }
 
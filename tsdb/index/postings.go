@@ -118,9 +118,9 @@ type PostingsStats struct {
 	NumLabelPairs           int
 }
 
-// Stats calculates the cardinality statistics from postings.
-func (p *MemPostings) Stats(label string) *PostingsStats {
-	const maxNumOfRecords = 10
+// Stats calculates the cardinality statistics from postings. The limit
+// bounds the number of records per statistic.
+func (p *MemPostings) Stats(label string, limit int) *PostingsStats {
 	var size uint64
 
 	p.mtx.RLock()
@@ -131,10 +131,10 @@ func (p *MemPostings) Stats(label string) *PostingsStats {
 	labelValuePairs := &maxHeap{}
 	numLabelPairs := 0
 
-	metrics.init(maxNumOfRecords)
-	labels.init(maxNumOfRecords)
-	labelValueLength.init(maxNumOfRecords)
-	labelValuePairs.init(maxNumOfRecords)
+	metrics.init(limit)
+	labels.init(limit)
+	labelValueLength.init(limit)
+	labelValuePairs.init(limit)
 
 	for n, e := range p.m {
 		if n == "" {
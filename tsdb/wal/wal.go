@@ -164,6 +164,61 @@ func OpenReadSegment(fn string) (*Segment, error) {
 	return &Segment{SegmentFile: f, i: k, dir: filepath.Dir(fn)}, nil
 }
 
+// SyncMode names a policy for when the WAL fsyncs the active segment.
+type SyncMode string
+
+const (
+	// SyncRotation fsyncs segments only when they are rotated or the WAL is
+	// closed. This is the historical behavior and the default.
+	SyncRotation SyncMode = "rotation"
+	// SyncAlways fsyncs the active segment after every Log call. This bounds
+	// the durability window to a single append batch at the cost of append
+	// throughput.
+	SyncAlways SyncMode = "always"
+	// SyncInterval fsyncs the active segment on the first Log call after
+	// Interval has elapsed since the previous fsync. An idle WAL is synced
+	// on segment rotation and close.
+	SyncInterval SyncMode = "interval"
+	// SyncBatchSize fsyncs the active segment after a fixed number of Log
+	// calls.
+	SyncBatchSize SyncMode = "batch-size"
+)
+
+// SyncPolicy describes when the WAL fsyncs the active segment beyond segment
+// rotation and close. Tighter policies shrink the window of acknowledged but
+// not yet durable records, looser ones increase append throughput.
+type SyncPolicy struct {
+	Mode SyncMode
+	// Interval between fsyncs. Used when Mode is SyncInterval.
+	Interval time.Duration
+	// BatchSize is the number of Log calls between fsyncs. Used when Mode is
+	// SyncBatchSize.
+	BatchSize int
+}
+
+// DefaultSyncPolicy returns the policy matching the historical WAL behavior.
+func DefaultSyncPolicy() SyncPolicy {
+	return SyncPolicy{Mode: SyncRotation}
+}
+
+// Validate returns an error if the policy is inconsistent.
+func (p SyncPolicy) Validate() error {
+	switch p.Mode {
+	case SyncRotation, SyncAlways:
+	case SyncInterval:
+		if p.Interval <= 0 {
+			return errors.New("sync interval must be positive")
+		}
+	case SyncBatchSize:
+		if p.BatchSize <= 0 {
+			return errors.New("sync batch size must be positive")
+		}
+	default:
+		return errors.Errorf("invalid sync mode %q", p.Mode)
+	}
+	return nil
+}
+
 // WAL is a write ahead log that stores records in segment files.
 // It must be read from start to end once before logging new data.
 // If an error occurs during read, the repair procedure must be called
@@ -188,17 +243,24 @@ type WAL struct {
 	compress    bool
 	snappyBuf   []byte
 
+	syncPolicy      SyncPolicy
+	unsyncedBatches int       // Log calls since the active segment was last fsynced.
+	lastSync        time.Time // Time of the last policy-triggered fsync.
+
 	metrics *walMetrics
 }
 
 type walMetrics struct {
-	fsyncDuration   prometheus.Summary
-	pageFlushes     prometheus.Counter
-	pageCompletions prometheus.Counter
-	truncateFail    prometheus.Counter
-	truncateTotal   prometheus.Counter
-	currentSegment  prometheus.Gauge
-	writesFailed    prometheus.Counter
+	fsyncDuration    prometheus.Summary
+	pageFlushes      prometheus.Counter
+	pageCompletions  prometheus.Counter
+	truncateFail     prometheus.Counter
+	truncateTotal    prometheus.Counter
+	currentSegment   prometheus.Gauge
+	writesFailed     prometheus.Counter
+	policySyncs      prometheus.Counter
+	unsyncedBatches  prometheus.Gauge
+	logBatchDuration prometheus.Summary
 }
 
 func newWALMetrics(r prometheus.Registerer) *walMetrics {
@@ -233,6 +295,19 @@ func newWALMetrics(r prometheus.Registerer) *walMetrics {
 		Name: "prometheus_tsdb_wal_writes_failed_total",
 		Help: "Total number of WAL writes that failed.",
 	})
+	m.policySyncs = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "prometheus_tsdb_wal_policy_fsyncs_total",
+		Help: "Total number of fsyncs of the active segment triggered by the configured sync policy.",
+	})
+	m.unsyncedBatches = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "prometheus_tsdb_wal_unsynced_log_batches",
+		Help: "Number of Log calls written since the active segment was last fsynced.",
+	})
+	m.logBatchDuration = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "prometheus_tsdb_wal_log_batch_duration_seconds",
+		Help:       "Duration of WAL Log calls, including any fsync triggered by the sync policy.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	})
 
 	if r != nil {
 		r.MustRegister(
@@ -243,6 +318,9 @@ func newWALMetrics(r prometheus.Registerer) *walMetrics {
 			m.truncateTotal,
 			m.currentSegment,
 			m.writesFailed,
+			m.policySyncs,
+			m.unsyncedBatches,
+			m.logBatchDuration,
 		)
 	}
 
@@ -257,9 +335,18 @@ func New(logger log.Logger, reg prometheus.Registerer, dir string, compress bool
 // NewSize returns a new WAL over the given directory.
 // New segments are created with the specified size.
 func NewSize(logger log.Logger, reg prometheus.Registerer, dir string, segmentSize int, compress bool) (*WAL, error) {
+	return NewSizeWithSyncPolicy(logger, reg, dir, segmentSize, compress, DefaultSyncPolicy())
+}
+
+// NewSizeWithSyncPolicy returns a new WAL over the given directory with the
+// given fsync policy. New segments are created with the specified size.
+func NewSizeWithSyncPolicy(logger log.Logger, reg prometheus.Registerer, dir string, segmentSize int, compress bool, syncPolicy SyncPolicy) (*WAL, error) {
 	if segmentSize%pageSize != 0 {
 		return nil, errors.New("invalid segment size")
 	}
+	if err := syncPolicy.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid sync policy")
+	}
 	if err := os.MkdirAll(dir, 0777); err != nil {
 		return nil, errors.Wrap(err, "create dir")
 	}
@@ -274,6 +361,7 @@ func NewSize(logger log.Logger, reg prometheus.Registerer, dir string, segmentSi
 		actorc:      make(chan func(), 100),
 		stopc:       make(chan chan struct{}),
 		compress:    compress,
+		syncPolicy:  syncPolicy,
 	}
 	w.metrics = newWALMetrics(reg)
 
@@ -487,6 +575,12 @@ func (w *WAL) nextSegment() error {
 		return err
 	}
 
+	// The previous segment is fsynced below, so the new segment starts with
+	// no unsynced batches.
+	w.unsyncedBatches = 0
+	w.metrics.unsyncedBatches.Set(0)
+	w.lastSync = time.Now()
+
 	// Don't block further writes by fsyncing the last segment.
 	w.actorc <- func() {
 		if err := w.fsync(prev); err != nil {
@@ -588,8 +682,12 @@ func (w *WAL) pagesPerSegment() int {
 // Log writes the records into the log.
 // Multiple records can be passed at once to reduce writes and increase throughput.
 func (w *WAL) Log(recs ...[]byte) error {
+	start := time.Now()
 	w.mtx.Lock()
 	defer w.mtx.Unlock()
+	defer func() {
+		w.metrics.logBatchDuration.Observe(time.Since(start).Seconds())
+	}()
 	// Callers could just implement their own list record format but adding
 	// a bit of extra logic here frees them from that overhead.
 	for i, r := range recs {
@@ -598,9 +696,40 @@ func (w *WAL) Log(recs ...[]byte) error {
 			return err
 		}
 	}
+	return w.applySyncPolicy()
+}
+
+// applySyncPolicy fsyncs the active segment if the sync policy demands it
+// after another completed Log batch. Must be called with the mutex held.
+func (w *WAL) applySyncPolicy() error {
+	w.unsyncedBatches++
+	switch w.syncPolicy.Mode {
+	case SyncAlways:
+		return w.syncActiveSegment()
+	case SyncBatchSize:
+		if w.unsyncedBatches >= w.syncPolicy.BatchSize {
+			return w.syncActiveSegment()
+		}
+	case SyncInterval:
+		if time.Since(w.lastSync) >= w.syncPolicy.Interval {
+			return w.syncActiveSegment()
+		}
+	}
+	w.metrics.unsyncedBatches.Set(float64(w.unsyncedBatches))
 	return nil
 }
 
+// syncActiveSegment fsyncs the active segment and resets the unsynced batch
+// accounting. Must be called with the mutex held.
+func (w *WAL) syncActiveSegment() error {
+	err := w.fsync(w.segment)
+	w.metrics.policySyncs.Inc()
+	w.unsyncedBatches = 0
+	w.metrics.unsyncedBatches.Set(0)
+	w.lastSync = time.Now()
+	return err
+}
+
 // log writes rec to the log and forces a flush of the current page if:
 // - the final record of a batch
 // - the record is bigger than the page size
@@ -15,7 +15,9 @@ package v1
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"math/rand"
 	"net"
@@ -27,6 +29,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -37,9 +40,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/route"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/adolli/prometheus/config"
 	"github.com/adolli/prometheus/pkg/exemplar"
+	"github.com/adolli/prometheus/pkg/rulefmt"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/pkg/textparse"
 	"github.com/adolli/prometheus/pkg/timestamp"
@@ -131,11 +136,28 @@ type RuntimeInfo struct {
 }
 
 type response struct {
-	Status    status      `json:"status"`
-	Data      interface{} `json:"data,omitempty"`
-	ErrorType errorType   `json:"errorType,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Warnings  []string    `json:"warnings,omitempty"`
+	Status            status      `json:"status"`
+	Data              interface{} `json:"data,omitempty"`
+	ErrorType         errorType   `json:"errorType,omitempty"`
+	Error             string      `json:"error,omitempty"`
+	Warnings          []string    `json:"warnings,omitempty"`
+	ContinuationToken string      `json:"continuationToken,omitempty"`
+}
+
+// paginated wraps the payload of a truncated result together with the
+// continuation token a client can pass to resume where this page ended.
+type paginated struct {
+	data  interface{}
+	token string
+}
+
+// withPagination attaches the continuation token for the next page, if any,
+// to the result payload.
+func withPagination(data interface{}, contToken string) interface{} {
+	if contToken == "" {
+		return data
+	}
+	return paginated{data: data, token: contToken}
 }
 
 type apiFuncResult struct {
@@ -151,9 +173,12 @@ type apiFunc func(r *http.Request) apiFuncResult
 type TSDBAdminStats interface {
 	CleanTombstones() error
 	Delete(mint, maxt int64, ms ...*labels.Matcher) error
+	DeleteBlock(id string) error
+	PinBlock(id string) error
+	UnpinBlock(id string) error
 	Snapshot(dir string, withHead bool) error
 
-	Stats(statsByLabelName string) (*tsdb.Stats, error)
+	Stats(statsByLabelName string, limit int) (*tsdb.Stats, error)
 }
 
 // API can register a set of endpoints in a router and handle
@@ -183,6 +208,14 @@ type API struct {
 
 	remoteWriteHandler http.Handler
 	remoteReadHandler  http.Handler
+
+	activeQueries *queryRegistry
+	codecs        []codec
+
+	dynamicConfigDir string
+	triggerReload    func() error
+	configLoader     func() (*config.Config, error)
+	drainRemoteWrite func(context.Context) error
 }
 
 func init() {
@@ -215,6 +248,10 @@ func NewAPI(
 	buildInfo *PrometheusVersion,
 	gatherer prometheus.Gatherer,
 	registerer prometheus.Registerer,
+	dynamicConfigDir string,
+	triggerReload func() error,
+	configLoader func() (*config.Config, error),
+	drainRemoteWrite func(context.Context) error,
 ) *API {
 	a := &API{
 		QueryEngine:       qe,
@@ -239,7 +276,15 @@ func NewAPI(
 		buildInfo:        buildInfo,
 		gatherer:         gatherer,
 
-		remoteReadHandler: remote.NewReadHandler(logger, registerer, q, configFunc, remoteReadSampleLimit, remoteReadConcurrencyLimit, remoteReadMaxBytesInFrame),
+		remoteReadHandler: remote.NewReadHandler(logger, registerer, q, eq, configFunc, remoteReadSampleLimit, remoteReadConcurrencyLimit, remoteReadMaxBytesInFrame),
+
+		activeQueries: newQueryRegistry(),
+		codecs:        []codec{protobufCodec{}},
+
+		dynamicConfigDir: dynamicConfigDir,
+		triggerReload:    triggerReload,
+		configLoader:     configLoader,
+		drainRemoteWrite: drainRemoteWrite,
 	}
 
 	if ap != nil {
@@ -271,7 +316,7 @@ func (api *API) Register(r *route.Router) {
 			}
 
 			if result.data != nil {
-				api.respond(w, result.data, result.warnings)
+				api.respond(w, r, result.data, result.warnings)
 				return
 			}
 			w.WriteHeader(http.StatusNoContent)
@@ -281,14 +326,31 @@ func (api *API) Register(r *route.Router) {
 		}.ServeHTTP)
 	}
 
+	// wrapQuery additionally assigns a correlation ID to the query, announces
+	// it in the X-Prometheus-Query-ID response header, and makes the query
+	// killable through the API for as long as it is running.
+	wrapQuery := func(f apiFunc) http.HandlerFunc {
+		hf := wrap(f)
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			queryID := api.activeQueries.register(cancel)
+			defer api.activeQueries.deregister(queryID)
+			w.Header().Set("X-Prometheus-Query-ID", queryID)
+			hf(w, r.WithContext(promql.ContextWithQueryID(ctx, queryID)))
+		}
+	}
+
 	r.Options("/*path", wrap(api.options))
 
-	r.Get("/query", wrap(api.query))
-	r.Post("/query", wrap(api.query))
-	r.Get("/query_range", wrap(api.queryRange))
-	r.Post("/query_range", wrap(api.queryRange))
+	r.Get("/query", wrapQuery(api.query))
+	r.Post("/query", wrapQuery(api.query))
+	r.Get("/query_range", wrapQuery(api.queryRange))
+	r.Post("/query_range", wrapQuery(api.queryRange))
 	r.Get("/query_exemplars", wrap(api.queryExemplars))
 	r.Post("/query_exemplars", wrap(api.queryExemplars))
+	r.Post("/query/:id/kill", wrap(api.killQuery))
+	r.Put("/query/:id/kill", wrap(api.killQuery))
 
 	r.Get("/labels", wrap(api.labelNames))
 	r.Post("/labels", wrap(api.labelNames))
@@ -305,10 +367,13 @@ func (api *API) Register(r *route.Router) {
 	r.Get("/metadata", wrap(api.metricMetadata))
 
 	r.Get("/status/config", wrap(api.serveConfig))
+	r.Get("/status/config/diff", wrap(api.serveConfigDiff))
 	r.Get("/status/runtimeinfo", wrap(api.serveRuntimeInfo))
 	r.Get("/status/buildinfo", wrap(api.serveBuildInfo))
 	r.Get("/status/flags", wrap(api.serveFlags))
 	r.Get("/status/tsdb", wrap(api.serveTSDBStatus))
+	r.Get("/status/cardinality", wrap(api.serveCardinalityStatus))
+	r.Get("/status/active_queries", wrap(api.serveActiveQueries))
 	r.Post("/read", api.ready(http.HandlerFunc(api.remoteRead)))
 	r.Post("/write", api.ready(http.HandlerFunc(api.remoteWrite)))
 
@@ -316,13 +381,29 @@ func (api *API) Register(r *route.Router) {
 	r.Get("/rules", wrap(api.rules))
 
 	// Admin APIs
+	r.Post("/admin/remote_write/drain", wrap(api.remoteWriteDrain))
+	r.Put("/admin/remote_write/drain", wrap(api.remoteWriteDrain))
+
 	r.Post("/admin/tsdb/delete_series", wrap(api.deleteSeries))
 	r.Post("/admin/tsdb/clean_tombstones", wrap(api.cleanTombstones))
 	r.Post("/admin/tsdb/snapshot", wrap(api.snapshot))
+	r.Post("/admin/tsdb/delete_block", wrap(api.deleteBlock))
+	r.Post("/admin/tsdb/pin_block", wrap(api.pinBlock))
+	r.Post("/admin/tsdb/unpin_block", wrap(api.unpinBlock))
 
 	r.Put("/admin/tsdb/delete_series", wrap(api.deleteSeries))
 	r.Put("/admin/tsdb/clean_tombstones", wrap(api.cleanTombstones))
 	r.Put("/admin/tsdb/snapshot", wrap(api.snapshot))
+	r.Put("/admin/tsdb/delete_block", wrap(api.deleteBlock))
+	r.Put("/admin/tsdb/pin_block", wrap(api.pinBlock))
+	r.Put("/admin/tsdb/unpin_block", wrap(api.unpinBlock))
+
+	r.Post("/admin/config/scrape_configs", wrap(api.updateDynamicScrapeConfig))
+	r.Put("/admin/config/scrape_configs", wrap(api.updateDynamicScrapeConfig))
+	r.Del("/admin/config/scrape_configs/:name", wrap(api.deleteDynamicScrapeConfig))
+	r.Post("/admin/config/rules/:name", wrap(api.updateDynamicRuleFile))
+	r.Put("/admin/config/rules/:name", wrap(api.updateDynamicRuleFile))
+	r.Del("/admin/config/rules/:name", wrap(api.deleteDynamicRuleFile))
 }
 
 type queryData struct {
@@ -347,6 +428,13 @@ func (api *API) query(r *http.Request) (result apiFuncResult) {
 		return invalidParamError(err, "time")
 	}
 	ctx := r.Context()
+	pref, err := parseReadConsistency(r)
+	if err != nil {
+		return invalidParamError(err, "read_consistency")
+	}
+	if pref != storage.ReadAll {
+		ctx = storage.ContextWithReadPreference(ctx, pref)
+	}
 	if to := r.FormValue("timeout"); to != "" {
 		var cancel context.CancelFunc
 		timeout, err := parseDuration(to)
@@ -427,6 +515,13 @@ func (api *API) queryRange(r *http.Request) (result apiFuncResult) {
 	}
 
 	ctx := r.Context()
+	pref, err := parseReadConsistency(r)
+	if err != nil {
+		return invalidParamError(err, "read_consistency")
+	}
+	if pref != storage.ReadAll {
+		ctx = storage.ContextWithReadPreference(ctx, pref)
+	}
 	if to := r.FormValue("timeout"); to != "" {
 		var cancel context.CancelFunc
 		timeout, err := parseDuration(to)
@@ -476,6 +571,14 @@ func (api *API) queryRange(r *http.Request) (result apiFuncResult) {
 	}, nil, res.Warnings, qry.Close}
 }
 
+func (api *API) killQuery(r *http.Request) apiFuncResult {
+	id := route.Param(r.Context(), "id")
+	if !api.activeQueries.kill(id) {
+		return apiFuncResult{nil, &apiError{errorNotFound, errors.Errorf("query %q is not running", id)}, nil, nil}
+	}
+	return apiFuncResult{fmt.Sprintf("query %q killed", id), nil, nil, nil}
+}
+
 func (api *API) queryExemplars(r *http.Request) apiFuncResult {
 	start, err := parseTimeParam(r, "start", minTime)
 	if err != nil {
@@ -546,6 +649,15 @@ func (api *API) labelNames(r *http.Request) apiFuncResult {
 		return apiFuncResult{nil, &apiError{errorBadData, err}, nil, nil}
 	}
 
+	limit, err := parseLimitParam(r.FormValue("limit"))
+	if err != nil {
+		return invalidParamError(err, "limit")
+	}
+	contAfter, err := parseContinueParam(r.FormValue("continue"))
+	if err != nil {
+		return invalidParamError(err, "continue")
+	}
+
 	q, err := api.Queryable.Querier(r.Context(), timestamp.FromTime(start), timestamp.FromTime(end))
 	if err != nil {
 		return apiFuncResult{nil, &apiError{errorExec, err}, nil, nil}
@@ -561,6 +673,7 @@ func (api *API) labelNames(r *http.Request) apiFuncResult {
 			Start: timestamp.FromTime(start),
 			End:   timestamp.FromTime(end),
 			Func:  "series", // There is no series function, this token is used for lookups that don't need samples.
+			Limit: limit,
 		}
 
 		labelNamesSet := make(map[string]struct{})
@@ -595,7 +708,8 @@ func (api *API) labelNames(r *http.Request) apiFuncResult {
 	if names == nil {
 		names = []string{}
 	}
-	return apiFuncResult{names, nil, warnings, nil}
+	names, contToken := paginate(names, contAfter, limit)
+	return apiFuncResult{withPagination(names, contToken), nil, warnings, nil}
 }
 
 func (api *API) labelValues(r *http.Request) (result apiFuncResult) {
@@ -620,6 +734,15 @@ func (api *API) labelValues(r *http.Request) (result apiFuncResult) {
 		return apiFuncResult{nil, &apiError{errorBadData, err}, nil, nil}
 	}
 
+	limit, err := parseLimitParam(r.FormValue("limit"))
+	if err != nil {
+		return invalidParamError(err, "limit")
+	}
+	contAfter, err := parseContinueParam(r.FormValue("continue"))
+	if err != nil {
+		return invalidParamError(err, "continue")
+	}
+
 	q, err := api.Queryable.Querier(r.Context(), timestamp.FromTime(start), timestamp.FromTime(end))
 	if err != nil {
 		return apiFuncResult{nil, &apiError{errorExec, err}, nil, nil}
@@ -671,7 +794,8 @@ func (api *API) labelValues(r *http.Request) (result apiFuncResult) {
 
 	sort.Strings(vals)
 
-	return apiFuncResult{vals, nil, warnings, closer}
+	vals, contToken := paginate(vals, contAfter, limit)
+	return apiFuncResult{withPagination(vals, contToken), nil, warnings, closer}
 }
 
 var (
@@ -704,6 +828,15 @@ func (api *API) series(r *http.Request) (result apiFuncResult) {
 		return invalidParamError(err, "match[]")
 	}
 
+	limit, err := parseLimitParam(r.FormValue("limit"))
+	if err != nil {
+		return invalidParamError(err, "limit")
+	}
+	contAfter, err := parseContinueParam(r.FormValue("continue"))
+	if err != nil {
+		return invalidParamError(err, "continue")
+	}
+
 	q, err := api.Queryable.Querier(r.Context(), timestamp.FromTime(start), timestamp.FromTime(end))
 	if err != nil {
 		return apiFuncResult{nil, &apiError{errorExec, err}, nil, nil}
@@ -724,6 +857,7 @@ func (api *API) series(r *http.Request) (result apiFuncResult) {
 		Start: timestamp.FromTime(start),
 		End:   timestamp.FromTime(end),
 		Func:  "series", // There is no series function, this token is used for lookups that don't need samples.
+		Limit: limit,
 	}
 
 	var sets []storage.SeriesSet
@@ -735,8 +869,17 @@ func (api *API) series(r *http.Request) (result apiFuncResult) {
 
 	set := storage.NewMergeSeriesSet(sets, storage.ChainedSeriesMerge)
 	metrics := []labels.Labels{}
+	var contToken string
 	for set.Next() {
-		metrics = append(metrics, set.At().Labels())
+		lbls := set.At().Labels()
+		if contAfter != "" && lbls.String() <= contAfter {
+			continue
+		}
+		if limit > 0 && len(metrics) == limit {
+			contToken = continuationToken(metrics[limit-1].String())
+			break
+		}
+		metrics = append(metrics, lbls)
 	}
 
 	warnings := set.Warnings()
@@ -744,7 +887,7 @@ func (api *API) series(r *http.Request) (result apiFuncResult) {
 		return apiFuncResult{nil, &apiError{errorExec, set.Err()}, warnings, closer}
 	}
 
-	return apiFuncResult{metrics, nil, warnings, closer}
+	return apiFuncResult{withPagination(metrics, contToken), nil, warnings, closer}
 }
 
 func (api *API) dropSeries(_ *http.Request) apiFuncResult {
@@ -1284,6 +1427,48 @@ func (api *API) serveFlags(_ *http.Request) apiFuncResult {
 	return apiFuncResult{api.flagsMap, nil, nil, nil}
 }
 
+type configDiffSection struct {
+	Changed bool   `json:"changed"`
+	Running string `json:"running,omitempty"`
+	OnDisk  string `json:"onDisk,omitempty"`
+}
+
+type configDiff struct {
+	Changed  bool                         `json:"changed"`
+	Sections map[string]configDiffSection `json:"sections"`
+}
+
+func (api *API) serveConfigDiff(_ *http.Request) apiFuncResult {
+	if api.configLoader == nil {
+		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("config diff is not available")}, nil, nil}
+	}
+	onDisk, err := api.configLoader()
+	if err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, errors.Wrap(err, "loading on-disk configuration")}, nil, nil}
+	}
+	onDiskSections, err := onDisk.SectionsYAML()
+	if err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
+	}
+	running := api.config()
+	runningSections, err := running.SectionsYAML()
+	if err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
+	}
+
+	diff := &configDiff{Sections: make(map[string]configDiffSection, len(runningSections))}
+	for name, run := range runningSections {
+		s := configDiffSection{Changed: run != onDiskSections[name]}
+		if s.Changed {
+			s.Running = run
+			s.OnDisk = onDiskSections[name]
+			diff.Changed = true
+		}
+		diff.Sections[name] = s
+	}
+	return apiFuncResult{diff, nil, nil, nil}
+}
+
 // stat holds the information about individual cardinality.
 type stat struct {
 	Name  string `json:"name"`
@@ -1318,7 +1503,7 @@ func convertStats(stats []index.Stat) []stat {
 }
 
 func (api *API) serveTSDBStatus(*http.Request) apiFuncResult {
-	s, err := api.db.Stats("__name__")
+	s, err := api.db.Stats("__name__", 10)
 	if err != nil {
 		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
 	}
@@ -1351,6 +1536,51 @@ func (api *API) serveTSDBStatus(*http.Request) apiFuncResult {
 	}, nil, nil, nil}
 }
 
+// cardinalityStatus has the cardinality statistics of the TSDB head.
+type cardinalityStatus struct {
+	NumSeries                   uint64 `json:"numSeries"`
+	NumLabelPairs               int    `json:"numLabelPairs"`
+	SeriesCountByMetricName     []stat `json:"seriesCountByMetricName"`
+	LabelValueCountByLabelName  []stat `json:"labelValueCountByLabelName"`
+	MemoryInBytesByLabelName    []stat `json:"memoryInBytesByLabelName"`
+	SeriesCountByLabelValuePair []stat `json:"seriesCountByLabelValuePair"`
+}
+
+func (api *API) serveCardinalityStatus(r *http.Request) apiFuncResult {
+	topN := 10
+	if s := r.FormValue("topN"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return invalidParamError(err, "topN")
+		}
+		if n < 1 {
+			return invalidParamError(errors.New("must be greater than 0"), "topN")
+		}
+		topN = n
+	}
+
+	s, err := api.db.Stats("__name__", topN)
+	if err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
+	}
+	return apiFuncResult{cardinalityStatus{
+		NumSeries:                   s.NumSeries,
+		NumLabelPairs:               s.IndexPostingStats.NumLabelPairs,
+		SeriesCountByMetricName:     convertStats(s.IndexPostingStats.CardinalityMetricsStats),
+		LabelValueCountByLabelName:  convertStats(s.IndexPostingStats.CardinalityLabelStats),
+		MemoryInBytesByLabelName:    convertStats(s.IndexPostingStats.LabelValueStats),
+		SeriesCountByLabelValuePair: convertStats(s.IndexPostingStats.LabelValuePairsStats),
+	}, nil, nil, nil}
+}
+
+func (api *API) serveActiveQueries(r *http.Request) apiFuncResult {
+	queries := api.QueryEngine.ActiveQueries()
+	if queries == nil {
+		queries = []promql.ActiveQuery{}
+	}
+	return apiFuncResult{queries, nil, nil, nil}
+}
+
 func (api *API) remoteRead(w http.ResponseWriter, r *http.Request) {
 	// This is only really for tests - this will never be nil IRL.
 	if api.remoteReadHandler != nil {
@@ -1368,6 +1598,22 @@ func (api *API) remoteWrite(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// remoteWriteDrain stops reading new WAL data and flushes all pending remote
+// write queues. The drain is bounded by the lifetime of the request context,
+// so clients control how long to wait beyond the regular flush deadline.
+func (api *API) remoteWriteDrain(r *http.Request) apiFuncResult {
+	if !api.enableAdmin {
+		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("admin APIs disabled")}, nil, nil}
+	}
+	if api.drainRemoteWrite == nil {
+		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("remote write drain is not available")}, nil, nil}
+	}
+	if err := api.drainRemoteWrite(r.Context()); err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, errors.Wrap(err, "draining remote write queues")}, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
 func (api *API) deleteSeries(r *http.Request) apiFuncResult {
 	if !api.enableAdmin {
 		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("admin APIs disabled")}, nil, nil}
@@ -1401,6 +1647,48 @@ func (api *API) deleteSeries(r *http.Request) apiFuncResult {
 	return apiFuncResult{nil, nil, nil, nil}
 }
 
+func (api *API) deleteBlock(r *http.Request) apiFuncResult {
+	if !api.enableAdmin {
+		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("admin APIs disabled")}, nil, nil}
+	}
+	id := r.FormValue("block")
+	if id == "" {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.New("no block parameter provided")}, nil, nil}
+	}
+	if err := api.db.DeleteBlock(id); err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
+func (api *API) pinBlock(r *http.Request) apiFuncResult {
+	if !api.enableAdmin {
+		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("admin APIs disabled")}, nil, nil}
+	}
+	id := r.FormValue("block")
+	if id == "" {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.New("no block parameter provided")}, nil, nil}
+	}
+	if err := api.db.PinBlock(id); err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
+func (api *API) unpinBlock(r *http.Request) apiFuncResult {
+	if !api.enableAdmin {
+		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("admin APIs disabled")}, nil, nil}
+	}
+	id := r.FormValue("block")
+	if id == "" {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.New("no block parameter provided")}, nil, nil}
+	}
+	if err := api.db.UnpinBlock(id); err != nil {
+		return apiFuncResult{nil, &apiError{errorInternal, err}, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
 func (api *API) snapshot(r *http.Request) apiFuncResult {
 	if !api.enableAdmin {
 		return apiFuncResult{nil, &apiError{errorUnavailable, errors.New("admin APIs disabled")}, nil, nil}
@@ -1446,18 +1734,168 @@ func (api *API) cleanTombstones(r *http.Request) apiFuncResult {
 	return apiFuncResult{nil, nil, nil, nil}
 }
 
-func (api *API) respond(w http.ResponseWriter, data interface{}, warnings storage.Warnings) {
+func (api *API) dynamicConfigDisabled() *apiError {
+	if !api.enableAdmin {
+		return &apiError{errorUnavailable, errors.New("admin APIs disabled")}
+	}
+	if api.dynamicConfigDir == "" {
+		return &apiError{errorUnavailable, errors.New("dynamic configuration needs to be enabled with --enable-feature=dynamic-config")}
+	}
+	return nil
+}
+
+// validDynamicName rejects names that would escape the managed directory or
+// produce surprising file names.
+func validDynamicName(name string) bool {
+	return name != "" && name == filepath.Base(name) && !strings.HasPrefix(name, ".")
+}
+
+func (api *API) writeDynamicFile(subdir, name string, content []byte) *apiError {
+	dir := filepath.Join(api.dynamicConfigDir, subdir)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return &apiError{errorInternal, errors.Wrap(err, "create dynamic config directory")}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".yml"), content, 0666); err != nil {
+		return &apiError{errorInternal, errors.Wrap(err, "write dynamic config file")}
+	}
+	return nil
+}
+
+func (api *API) deleteDynamicFile(subdir, name string) *apiError {
+	if err := os.Remove(filepath.Join(api.dynamicConfigDir, subdir, name+".yml")); err != nil {
+		if os.IsNotExist(err) {
+			return &apiError{errorNotFound, errors.Errorf("no dynamic config named %q", name)}
+		}
+		return &apiError{errorInternal, errors.Wrap(err, "delete dynamic config file")}
+	}
+	return nil
+}
+
+func (api *API) reloadDynamicConfig() *apiError {
+	if err := api.triggerReload(); err != nil {
+		return &apiError{errorInternal, errors.Wrap(err, "reload configuration")}
+	}
+	return nil
+}
+
+func (api *API) updateDynamicScrapeConfig(r *http.Request) apiFuncResult {
+	if apiErr := api.dynamicConfigDisabled(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Wrap(err, "read request body")}, nil, nil}
+	}
+	var sc config.ScrapeConfig
+	if err := yaml.UnmarshalStrict(content, &sc); err != nil {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Wrap(err, "parse scrape config")}, nil, nil}
+	}
+	if !validDynamicName(sc.JobName) {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Errorf("invalid job name %q", sc.JobName)}, nil, nil}
+	}
+	if apiErr := api.writeDynamicFile(config.DynamicScrapeConfigsDir, sc.JobName, content); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	if apiErr := api.reloadDynamicConfig(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
+func (api *API) deleteDynamicScrapeConfig(r *http.Request) apiFuncResult {
+	if apiErr := api.dynamicConfigDisabled(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	name := route.Param(r.Context(), "name")
+	if !validDynamicName(name) {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Errorf("invalid job name %q", name)}, nil, nil}
+	}
+	if apiErr := api.deleteDynamicFile(config.DynamicScrapeConfigsDir, name); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	if apiErr := api.reloadDynamicConfig(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
+func (api *API) updateDynamicRuleFile(r *http.Request) apiFuncResult {
+	if apiErr := api.dynamicConfigDisabled(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	name := route.Param(r.Context(), "name")
+	if !validDynamicName(name) {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Errorf("invalid rule file name %q", name)}, nil, nil}
+	}
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Wrap(err, "read request body")}, nil, nil}
+	}
+	if _, errs := rulefmt.Parse(content); len(errs) > 0 {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Wrap(errs[0], "parse rule file")}, nil, nil}
+	}
+	if apiErr := api.writeDynamicFile(config.DynamicRulesDir, name, content); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	if apiErr := api.reloadDynamicConfig(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
+func (api *API) deleteDynamicRuleFile(r *http.Request) apiFuncResult {
+	if apiErr := api.dynamicConfigDisabled(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	name := route.Param(r.Context(), "name")
+	if !validDynamicName(name) {
+		return apiFuncResult{nil, &apiError{errorBadData, errors.Errorf("invalid rule file name %q", name)}, nil, nil}
+	}
+	if apiErr := api.deleteDynamicFile(config.DynamicRulesDir, name); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	if apiErr := api.reloadDynamicConfig(); apiErr != nil {
+		return apiFuncResult{nil, apiErr, nil, nil}
+	}
+	return apiFuncResult{nil, nil, nil, nil}
+}
+
+func (api *API) respond(w http.ResponseWriter, req *http.Request, data interface{}, warnings storage.Warnings) {
 	statusMessage := statusSuccess
 	var warningStrings []string
 	for _, warning := range warnings {
 		warningStrings = append(warningStrings, warning.Error())
 	}
-	json := jsoniter.ConfigCompatibleWithStandardLibrary
-	b, err := json.Marshal(&response{
+
+	// Query results may be serialized through a negotiated codec instead of
+	// the default JSON response envelope.
+	if qd, ok := data.(*queryData); ok && req != nil {
+		if c := api.negotiateCodec(req); c != nil {
+			b, err := c.encode(qd)
+			if err != nil {
+				level.Error(api.logger).Log("msg", "error encoding response", "contentType", c.contentType(), "err", err)
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+			w.Header().Set("Content-Type", c.contentType())
+			w.WriteHeader(http.StatusOK)
+			if n, err := w.Write(b); err != nil {
+				level.Error(api.logger).Log("msg", "error writing response", "bytesWritten", n, "err", err)
+			}
+			return
+		}
+	}
+	resp := &response{
 		Status:   statusMessage,
 		Data:     data,
 		Warnings: warningStrings,
-	})
+	}
+	if p, ok := data.(paginated); ok {
+		resp.Data = p.data
+		resp.ContinuationToken = p.token
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	b, err := json.Marshal(resp)
 	if err != nil {
 		level.Error(api.logger).Log("msg", "error marshaling json response", "err", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -1471,6 +1909,21 @@ func (api *API) respond(w http.ResponseWriter, data interface{}, warnings storag
 	}
 }
 
+// negotiateCodec returns the first registered codec whose content type occurs
+// in the request's Accept header, or nil if the client wants JSON.
+func (api *API) negotiateCodec(req *http.Request) codec {
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return nil
+	}
+	for _, c := range api.codecs {
+		if strings.Contains(accept, c.contentType()) {
+			return c
+		}
+	}
+	return nil
+}
+
 func (api *API) respondError(w http.ResponseWriter, apiErr *apiError, data interface{}) {
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	b, err := json.Marshal(&response{
@@ -1580,6 +2033,115 @@ OUTER:
 	return matcherSets, nil
 }
 
+// queryRegistry tracks the cancel functions of currently running queries by
+// their assigned correlation ID so they can be killed through the API.
+type queryRegistry struct {
+	mtx     sync.Mutex
+	counter uint64
+	queries map[string]context.CancelFunc
+}
+
+func newQueryRegistry() *queryRegistry {
+	return &queryRegistry{queries: map[string]context.CancelFunc{}}
+}
+
+// register assigns a new ID to the query belonging to the given cancel
+// function and starts tracking it.
+func (qr *queryRegistry) register(cancel context.CancelFunc) string {
+	qr.mtx.Lock()
+	defer qr.mtx.Unlock()
+	qr.counter++
+	id := strconv.FormatUint(qr.counter, 10)
+	qr.queries[id] = cancel
+	return id
+}
+
+func (qr *queryRegistry) deregister(id string) {
+	qr.mtx.Lock()
+	defer qr.mtx.Unlock()
+	delete(qr.queries, id)
+}
+
+// kill cancels the query with the given ID. It reports whether such a query
+// was running.
+func (qr *queryRegistry) kill(id string) bool {
+	qr.mtx.Lock()
+	defer qr.mtx.Unlock()
+	cancel, ok := qr.queries[id]
+	if !ok {
+		return false
+	}
+	cancel()
+	return true
+}
+
+// parseReadConsistency extracts the storage read preference from the
+// read_consistency form parameter or the X-Prometheus-Read-Consistency
+// header, the parameter taking precedence.
+func parseReadConsistency(r *http.Request) (storage.ReadPreference, error) {
+	s := r.FormValue("read_consistency")
+	if s == "" {
+		s = r.Header.Get("X-Prometheus-Read-Consistency")
+	}
+	switch pref := storage.ReadPreference(s); pref {
+	case "":
+		return storage.ReadAll, nil
+	case storage.ReadAll, storage.ReadLocal, storage.ReadRemote:
+		return pref, nil
+	}
+	return "", errors.Errorf("invalid read consistency %q, must be one of %q, %q, %q", s, storage.ReadAll, storage.ReadLocal, storage.ReadRemote)
+}
+
+// parseLimitParam returns the maximum number of results the client wants to
+// receive, 0 meaning no limit.
+func parseLimitParam(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot parse %q to a valid limit", s)
+	}
+	if limit < 0 {
+		return 0, errors.New("limit must not be negative")
+	}
+	return limit, nil
+}
+
+// parseContinueParam decodes the opaque continuation token handed out by a
+// previous paginated call.
+func parseContinueParam(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	tok, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse continuation token")
+	}
+	return string(tok), nil
+}
+
+func continuationToken(last string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(last))
+}
+
+// paginate applies the continuation token and limit to the sorted items. It
+// returns the requested page and, if results were truncated, the token for
+// the next page.
+func paginate(items []string, contAfter string, limit int) ([]string, string) {
+	if contAfter != "" {
+		i := sort.SearchStrings(items, contAfter)
+		if i < len(items) && items[i] == contAfter {
+			i++
+		}
+		items = items[i:]
+	}
+	if limit > 0 && len(items) > limit {
+		return items[:limit], continuationToken(items[limit-1])
+	}
+	return items, ""
+}
+
 // marshalPointJSON writes `[ts, "val"]`.
 func marshalPointJSON(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	p := *((*promql.Point)(ptr))
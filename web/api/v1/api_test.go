@@ -810,6 +810,40 @@ func testEndpoints(t *testing.T, api *API, tr *testTargetRetriever, es storage.E
 				labels.FromStrings("__name__", "test_metric4", "foo", "boo"),
 			},
 		},
+		// Limit and continuation token pagination.
+		{
+			endpoint: api.series,
+			query: url.Values{
+				"match[]": []string{`test_metric4{foo=~".+o$"}`, `test_metric4{dup=~"^1"}`},
+				"limit":   []string{"2"},
+			},
+			response: paginated{
+				data: []labels.Labels{
+					labels.FromStrings("__name__", "test_metric4", "dup", "1", "foo", "bar"),
+					labels.FromStrings("__name__", "test_metric4", "dup", "1", "foo", "boo"),
+				},
+				token: continuationToken(labels.FromStrings("__name__", "test_metric4", "dup", "1", "foo", "boo").String()),
+			},
+		},
+		{
+			endpoint: api.series,
+			query: url.Values{
+				"match[]":  []string{`test_metric4{foo=~".+o$"}`, `test_metric4{dup=~"^1"}`},
+				"limit":    []string{"2"},
+				"continue": []string{continuationToken(labels.FromStrings("__name__", "test_metric4", "dup", "1", "foo", "boo").String())},
+			},
+			response: []labels.Labels{
+				labels.FromStrings("__name__", "test_metric4", "foo", "boo"),
+			},
+		},
+		{
+			endpoint: api.series,
+			query: url.Values{
+				"match[]": []string{`test_metric2`},
+				"limit":   []string{"-1"},
+			},
+			errType: errorBadData,
+		},
 		{
 			endpoint: api.series,
 			query: url.Values{
@@ -2099,8 +2133,11 @@ type fakeDB struct {
 
 func (f *fakeDB) CleanTombstones() error                               { return f.err }
 func (f *fakeDB) Delete(mint, maxt int64, ms ...*labels.Matcher) error { return f.err }
+func (f *fakeDB) DeleteBlock(id string) error                          { return f.err }
+func (f *fakeDB) PinBlock(id string) error                             { return f.err }
+func (f *fakeDB) UnpinBlock(id string) error                           { return f.err }
 func (f *fakeDB) Snapshot(dir string, withHead bool) error             { return f.err }
-func (f *fakeDB) Stats(statsByLabelName string) (_ *tsdb.Stats, retErr error) {
+func (f *fakeDB) Stats(statsByLabelName string, limit int) (_ *tsdb.Stats, retErr error) {
 	dbDir, err := ioutil.TempDir("", "tsdb-api-ready")
 	if err != nil {
 		return nil, err
@@ -2114,7 +2151,7 @@ func (f *fakeDB) Stats(statsByLabelName string) (_ *tsdb.Stats, retErr error) {
 	opts := tsdb.DefaultHeadOptions()
 	opts.ChunkRange = 1000
 	h, _ := tsdb.NewHead(nil, nil, nil, opts)
-	return h.Stats(statsByLabelName), nil
+	return h.Stats(statsByLabelName, limit), nil
 }
 
 func TestAdminEndpoints(t *testing.T) {
@@ -2303,7 +2340,7 @@ func TestAdminEndpoints(t *testing.T) {
 func TestRespondSuccess(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		api := API{}
-		api.respond(w, "test", nil)
+		api.respond(w, r, "test", nil)
 	}))
 	defer s.Close()
 
@@ -2553,6 +2590,25 @@ func TestParseDuration(t *testing.T) {
 	}
 }
 
+func TestKillQuery(t *testing.T) {
+	api := &API{activeQueries: newQueryRegistry()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	id := api.activeQueries.register(cancel)
+
+	req, err := http.NewRequest("POST", "http://example.com", nil)
+	require.NoError(t, err)
+
+	res := api.killQuery(req.WithContext(route.WithParam(req.Context(), "id", id)))
+	assertAPIError(t, res.err, errorNone)
+	require.Error(t, ctx.Err(), "expected the query context to be canceled")
+
+	api.activeQueries.deregister(id)
+	res = api.killQuery(req.WithContext(route.WithParam(req.Context(), "id", id)))
+	assertAPIError(t, res.err, errorNotFound)
+}
+
 func TestOptionsMethod(t *testing.T) {
 	r := route.New()
 	api := &API{ready: func(f http.HandlerFunc) http.HandlerFunc { return f }}
@@ -2688,7 +2744,7 @@ func TestRespond(t *testing.T) {
 	for _, c := range cases {
 		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			api := API{}
-			api.respond(w, c.response, nil)
+			api.respond(w, r, c.response, nil)
 		}))
 		defer s.Close()
 
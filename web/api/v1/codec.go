@@ -0,0 +1,76 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/adolli/prometheus/pkg/labels"
+	"github.com/adolli/prometheus/prompb"
+	"github.com/adolli/prometheus/promql"
+)
+
+// A codec serializes query results in a format negotiated through the
+// request's Accept header. JSON remains the default and is handled directly
+// in respond. Further codecs (e.g. Arrow IPC) can be appended to API.codecs.
+type codec interface {
+	// contentType returns the MIME type advertised and produced by the codec.
+	contentType() string
+	// encode serializes the given query payload.
+	encode(data *queryData) ([]byte, error)
+}
+
+// protobufCodec serializes matrix, vector and scalar results as a
+// prompb.QueryResult message, mirroring the remote read wire format. This is
+// considerably cheaper to produce and to parse than JSON for large matrices.
+type protobufCodec struct{}
+
+func (protobufCodec) contentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) encode(data *queryData) ([]byte, error) {
+	res := &prompb.QueryResult{}
+	switch v := data.Result.(type) {
+	case promql.Matrix:
+		for _, s := range v {
+			ts := &prompb.TimeSeries{Labels: protoLabels(s.Metric)}
+			for _, p := range s.Points {
+				ts.Samples = append(ts.Samples, prompb.Sample{Timestamp: p.T, Value: p.V})
+			}
+			res.Timeseries = append(res.Timeseries, ts)
+		}
+	case promql.Vector:
+		for _, s := range v {
+			res.Timeseries = append(res.Timeseries, &prompb.TimeSeries{
+				Labels:  protoLabels(s.Metric),
+				Samples: []prompb.Sample{{Timestamp: s.T, Value: s.V}},
+			})
+		}
+	case promql.Scalar:
+		res.Timeseries = append(res.Timeseries, &prompb.TimeSeries{
+			Samples: []prompb.Sample{{Timestamp: v.T, Value: v.V}},
+		})
+	default:
+		return nil, errors.Errorf("protobuf encoding is not supported for %s results", data.ResultType)
+	}
+	return proto.Marshal(res)
+}
+
+func protoLabels(lbls labels.Labels) []prompb.Label {
+	result := make([]prompb.Label, 0, len(lbls))
+	for _, l := range lbls {
+		result = append(result, prompb.Label{Name: l.Name, Value: l.Value})
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adolli/prometheus/pkg/labels"
+	"github.com/adolli/prometheus/prompb"
+	"github.com/adolli/prometheus/promql"
+	"github.com/adolli/prometheus/promql/parser"
+)
+
+func TestProtobufCodec(t *testing.T) {
+	data := &queryData{
+		ResultType: parser.ValueTypeMatrix,
+		Result: promql.Matrix{
+			promql.Series{
+				Metric: labels.FromStrings("__name__", "test_metric", "foo", "bar"),
+				Points: []promql.Point{{T: 0, V: 1}, {T: 1000, V: 2}},
+			},
+		},
+	}
+
+	b, err := protobufCodec{}.encode(data)
+	require.NoError(t, err)
+
+	var res prompb.QueryResult
+	require.NoError(t, proto.Unmarshal(b, &res))
+	require.Equal(t, &prompb.QueryResult{
+		Timeseries: []*prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "test_metric"},
+					{Name: "foo", Value: "bar"},
+				},
+				Samples: []prompb.Sample{{Timestamp: 0, Value: 1}, {Timestamp: 1000, Value: 2}},
+			},
+		},
+	}, &res)
+}
+
+func TestProtobufCodecUnsupportedType(t *testing.T) {
+	_, err := protobufCodec{}.encode(&queryData{
+		ResultType: parser.ValueTypeString,
+		Result:     promql.String{T: 0, V: "test"},
+	})
+	require.Error(t, err)
+}
@@ -17,15 +17,18 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 
 	"github.com/go-kit/kit/log/level"
 	"github.com/gogo/protobuf/proto"
+	pb_ts "github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
 
+	"github.com/adolli/prometheus/pkg/exemplar"
 	"github.com/adolli/prometheus/pkg/labels"
 	"github.com/adolli/prometheus/pkg/timestamp"
 	"github.com/adolli/prometheus/pkg/value"
@@ -66,13 +69,27 @@ func (h *Handler) federation(w http.ResponseWriter, req *http.Request) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		// Selectors consisting only of negative matchers are not selectable
+		// on their own as they also match series without the labels at all.
+		// Anchor them on the metric name so that purely negative selectors
+		// like {job!="federate"} work as expected.
+		anchored := false
+		for _, m := range matchers {
+			if !m.Matches("") {
+				anchored = true
+				break
+			}
+		}
+		if !anchored {
+			matchers = append(matchers, labels.MustNewMatcher(labels.MatchNotEqual, labels.MetricName, ""))
+		}
 		matcherSets = append(matcherSets, matchers)
 	}
 
 	var (
 		mint   = timestamp.FromTime(h.now().Time().Add(-h.lookbackDelta))
 		maxt   = timestamp.FromTime(h.now().Time())
-		format = expfmt.Negotiate(req.Header)
+		format = expfmt.NegotiateIncludingOpenMetrics(req.Header)
 		enc    = expfmt.NewEncoder(w, format)
 	)
 	w.Header().Set("Content-Type", string(format))
@@ -89,6 +106,28 @@ func (h *Handler) federation(w http.ResponseWriter, req *http.Request) {
 	}
 	defer q.Close()
 
+	// Exemplars are only defined for counters and histogram buckets in the
+	// exposition formats, so we attach them to series following the counter
+	// naming convention and only when OpenMetrics was negotiated.
+	exemplars := map[string][]exemplar.Exemplar{}
+	if format == expfmt.FmtOpenMetrics && h.exemplarStorage != nil {
+		eq, err := h.exemplarStorage.ExemplarQuerier(req.Context())
+		if err != nil {
+			federationErrors.Inc()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res, err := eq.Select(mint, maxt, matcherSets...)
+		if err != nil {
+			federationErrors.Inc()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, r := range res {
+			exemplars[r.SeriesLabels.String()] = r.Exemplars
+		}
+	}
+
 	vec := make(promql.Vector, 0, 8000)
 
 	hints := &storage.SelectHints{Start: mint, End: maxt}
@@ -162,9 +201,7 @@ func (h *Handler) federation(w http.ResponseWriter, req *http.Request) {
 	for _, s := range vec {
 		nameSeen := false
 		globalUsed := map[string]struct{}{}
-		protMetric := &dto.Metric{
-			Untyped: &dto.Untyped{},
-		}
+		protMetric := &dto.Metric{}
 
 		for _, l := range s.Metric {
 			if l.Value == "" {
@@ -188,8 +225,12 @@ func (h *Handler) federation(w http.ResponseWriter, req *http.Request) {
 						return
 					}
 				}
+				typ := dto.MetricType_UNTYPED
+				if format == expfmt.FmtOpenMetrics && strings.HasSuffix(l.Value, "_total") {
+					typ = dto.MetricType_COUNTER
+				}
 				protMetricFam = &dto.MetricFamily{
-					Type: dto.MetricType_UNTYPED.Enum(),
+					Type: typ.Enum(),
 					Name: proto.String(l.Value),
 				}
 				lastMetricName = l.Value
@@ -219,7 +260,16 @@ func (h *Handler) federation(w http.ResponseWriter, req *http.Request) {
 		}
 
 		protMetric.TimestampMs = proto.Int64(s.T)
-		protMetric.Untyped.Value = proto.Float64(s.V)
+		if protMetricFam.GetType() == dto.MetricType_COUNTER {
+			protMetric.Counter = &dto.Counter{Value: proto.Float64(s.V)}
+			if es := exemplars[s.Metric.String()]; len(es) > 0 {
+				// The exposition formats allow a single exemplar per
+				// sample, so ship the most recent one.
+				protMetric.Counter.Exemplar = protoExemplar(es[len(es)-1])
+			}
+		} else {
+			protMetric.Untyped = &dto.Untyped{Value: proto.Float64(s.V)}
+		}
 
 		protMetricFam.Metric = append(protMetricFam.Metric, protMetric)
 	}
@@ -230,6 +280,27 @@ func (h *Handler) federation(w http.ResponseWriter, req *http.Request) {
 			level.Error(h.logger).Log("msg", "federation failed", "err", err)
 		}
 	}
+	// OpenMetrics responses are terminated with an EOF marker.
+	if closer, ok := enc.(expfmt.Closer); ok {
+		if err := closer.Close(); err != nil {
+			federationErrors.Inc()
+			level.Error(h.logger).Log("msg", "federation failed", "err", err)
+		}
+	}
+}
+
+func protoExemplar(e exemplar.Exemplar) *dto.Exemplar {
+	pe := &dto.Exemplar{Value: proto.Float64(e.Value)}
+	for _, l := range e.Labels {
+		pe.Label = append(pe.Label, &dto.LabelPair{
+			Name:  proto.String(l.Name),
+			Value: proto.String(l.Value),
+		})
+	}
+	if e.HasTs {
+		pe.Timestamp = &pb_ts.Timestamp{Seconds: e.Ts / 1000, Nanos: int32(e.Ts%1000) * 1e6}
+	}
+	return pe
 }
 
 // byName makes a model.Vector sortable by metric name.
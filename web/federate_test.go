@@ -150,6 +150,19 @@ test_metric2{foo="boo",instance="i"} 1 6000000
 test_metric_old{instance=""} 981 5880000
 # TYPE test_metric_without_labels untyped
 test_metric_without_labels{instance=""} 1001 6000000
+`,
+	},
+	"negative matchers only": {
+		params: "match[]={foo!='bar'}",
+		code:   200,
+		body: `# TYPE test_metric1 untyped
+test_metric1{foo="boo",instance="i"} 1 6000000
+# TYPE test_metric2 untyped
+test_metric2{foo="boo",instance="i"} 1 6000000
+# TYPE test_metric_old untyped
+test_metric_old{instance=""} 981 5880000
+# TYPE test_metric_without_labels untyped
+test_metric_without_labels{instance=""} 1001 6000000
 `,
 	},
 	"external labels are added if not already present": {
@@ -242,6 +242,9 @@ type Options struct {
 	ConsoleLibrariesPath       string
 	EnableLifecycle            bool
 	EnableAdminAPI             bool
+	DynamicConfigDir           string
+	ConfigLoader               func() (*config.Config, error)
+	DrainRemoteWrite           func(context.Context) error
 	PageTitle                  string
 	RemoteReadSampleLimit      int
 	RemoteReadConcurrencyLimit int
@@ -332,6 +335,14 @@ func New(logger log.Logger, o *Options) *Handler {
 		h.versionInfo,
 		o.Gatherer,
 		o.Registerer,
+		h.options.DynamicConfigDir,
+		func() error {
+			rc := make(chan error)
+			h.reloadCh <- rc
+			return <-rc
+		},
+		h.options.ConfigLoader,
+		h.options.DrainRemoteWrite,
 	)
 
 	if o.RoutePrefix != "/" {
@@ -789,7 +800,7 @@ func (h *Handler) status(w http.ResponseWriter, r *http.Request) {
 	}
 
 	startTime := time.Now().UnixNano()
-	s, err := h.localStorage.Stats("__name__")
+	s, err := h.localStorage.Stats("__name__", 10)
 	if err != nil {
 		if errors.Cause(err) == tsdb.ErrNotReady {
 			http.Error(w, tsdb.ErrNotReady.Error(), http.StatusServiceUnavailable)